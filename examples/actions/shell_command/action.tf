@@ -0,0 +1,31 @@
+action "shell_command" "example" {
+  config {
+    inputs = {
+      url = "https://example.com"
+    }
+    os_commands = {
+      default = {
+        invoke = {
+          command = <<-EOF
+            set -euo pipefail
+            url="$(jq --raw-output '.url' <<<"$${TF_SCRIPT_INPUTS}")"
+            echo "[INFO] Flushing the cache for $${url}."
+            my-cli cache flush --url "$${url}"
+            echo "[INFO] Cache flushed."
+          EOF
+        }
+      }
+    }
+  }
+}
+
+resource "terraform_data" "example" {
+  input = var.release_version
+
+  lifecycle {
+    action_trigger {
+      events  = [after_update]
+      actions = [action.shell_command.example]
+    }
+  }
+}
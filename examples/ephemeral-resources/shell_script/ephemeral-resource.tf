@@ -0,0 +1,38 @@
+ephemeral "shell_script" "example" {
+  inputs = {
+    role = "deployer"
+  }
+  os_commands = {
+    default = {
+      open = {
+        command = <<-EOF
+          set -euo pipefail
+          role="$(jq --raw-output '.role' <<<"$${TF_SCRIPT_INPUTS}")"
+          token="$(my-cli token create --role "$${role}" --ttl 15m)"
+          renew_at="$(date -u -d '+10 minutes' '+%Y-%m-%dT%H:%M:%SZ')"
+          jq --null-input --arg token "$${token}" --arg renew_at "$${renew_at}" '{"token":$token,"__meta":{"renew_at":$renew_at}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      renew = {
+        command = <<-EOF
+          set -euo pipefail
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
+          my-cli token renew "$${token}" --ttl 15m
+          renew_at="$(date -u -d '+10 minutes' '+%Y-%m-%dT%H:%M:%SZ')"
+          jq --null-input --arg token "$${token}" --arg renew_at "$${renew_at}" '{"token":$token,"__meta":{"renew_at":$renew_at}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      close = {
+        command = <<-EOF
+          set -euo pipefail
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
+          my-cli token revoke "$${token}"
+        EOF
+      }
+    }
+  }
+}
+
+provider "example" {
+  token = ephemeral.shell_script.example.output.token
+}
@@ -0,0 +1,7 @@
+output "example" {
+  value = provider::shell::exec(<<-EOF
+    set -euo pipefail
+    jq --compact-output '{ greeting: ("Hello " + .name) }' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+  EOF
+  , { name = "World" })
+}
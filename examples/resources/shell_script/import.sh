@@ -0,0 +1,2 @@
+# The import ID is passed to the import command via the TF_SCRIPT_IMPORT_ID environment variable.
+terraform import shell_script.example foo
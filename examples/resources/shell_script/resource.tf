@@ -56,6 +56,13 @@ resource "shell_script" "example" {
           rm -f "$${path}"
         EOF
       }
+      import = {
+        command = <<-EOF
+          set -euo pipefail
+          path="/tmp/$${TF_SCRIPT_IMPORT_ID}"
+          printf '{"inputs":{"file_name":"%s"},"output":{"exists": true,"path":"%s"}}' "$${TF_SCRIPT_IMPORT_ID}" "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
     }
     windows = {
       plan = {
@@ -110,6 +117,13 @@ resource "shell_script" "example" {
           Remove-Item -Path $path -Force -ErrorAction Ignore
         EOF
       }
+      import = {
+        command = <<-EOF
+          $fileName = $env:TF_SCRIPT_IMPORT_ID
+          $path = "$env:TEMP\$fileName"
+          @{inputs=@{file_name=$fileName}; output=@{exists=$true; path=$path}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
     }
   }
 }
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/action/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
+)
+
+var (
+	_ action.Action                   = &CommandAction{}
+	_ action.ActionWithConfigure      = &CommandAction{}
+	_ action.ActionWithValidateConfig = &CommandAction{}
+)
+
+// NewCommandAction creates a new command action.
+func NewCommandAction() action.Action {
+	return &CommandAction{}
+}
+
+// CommandAction defines the action implementation.
+type CommandAction struct {
+	providerData *ShellProviderData
+}
+
+// CommandActionModel describes the action data model.
+type CommandActionModel struct {
+	Environment            types.Map      `tfsdk:"environment"`
+	InheritEnvironment     types.String   `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List     `tfsdk:"environment_passthrough"`
+	WorkingDirectory       types.String   `tfsdk:"working_directory"`
+	InputMode              types.String   `tfsdk:"input_mode"`
+	InputsSchema           types.String   `tfsdk:"inputs_schema"`
+	Inputs                 types.Dynamic  `tfsdk:"inputs"`
+	OSCommands             types.Map      `tfsdk:"os_commands"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
+}
+
+// InvokeCommandModel describes a set of action commands.
+type InvokeCommandModel struct {
+	Invoke CommandModel `tfsdk:"invoke"`
+}
+
+// Metadata returns the action metadata.
+func (a *CommandAction) Metadata(_ context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = fmt.Sprintf("%s_command", req.ProviderTypeName)
+}
+
+// Schema returns the action schema.
+func (a *CommandAction) Schema(ctx context.Context, _ action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description:         "The Shell command action allows you to execute an arbitrary command as an imperative one-off operation without anything being persisted to the Terraform state.",
+		MarkdownDescription: "The _Shell_ command action (`shell_command`) allows you to execute an arbitrary command as an imperative one-off operation without anything being persisted to the _Terraform_ state. Any command output lines prefixed with a log level (e.g. `[INFO]`) are reported as action progress. If the command exits with a non-zero code the provider will read any text from the file defined by the `TF_SCRIPT_ERROR` environment variable and return it as part of the error diagnostics.",
+		Attributes: map[string]schema.Attribute{
+			"environment": schema.MapAttribute{
+				Description:         "The environment variables to set when executing the command; to be combined with the OS environment and the provider environment.",
+				MarkdownDescription: "The environment variables to set when executing the command; to be combined with the OS environment and the provider environment.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"inherit_environment": schema.StringAttribute{
+				Description:         "How the OS environment is inherited by the command; this can be one of all, none or allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "How the OS environment is inherited by the command; this can be one of `all`, `none` or `allowlist`. This defaults to the provider value if not set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inheritEnvironmentValues...),
+				},
+			},
+			"environment_passthrough": schema.ListAttribute{
+				Description:         "The OS environment variable names or glob patterns to inherit when inherit_environment is allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "The OS environment variable names or glob patterns (e.g. `AWS_*`) to inherit when `inherit_environment` is `allowlist`. This defaults to the provider value if not set.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"working_directory": schema.StringAttribute{
+				Description:         "The working directory to use when executing the command; this will default to the Terraform working directory.",
+				MarkdownDescription: "The working directory to use when executing the command; this will default to the _Terraform_ working directory.",
+				Optional:            true,
+			},
+			"input_mode": schema.StringAttribute{
+				Description:         "How the JSON inputs are passed to the command; this can be one of env, file or stdin. This defaults to env.",
+				MarkdownDescription: "How the JSON inputs are passed to the command; this can be one of `env`, `file` or `stdin`. This defaults to `env`, which sets the `TF_SCRIPT_*` environment variables. When set to `file` the JSON is written to temporary files with their paths in the matching `TF_SCRIPT_*_FILE` environment variables, and when set to `stdin` a single JSON document with the `lifecycle`, `inputs`, `sensitive_inputs`, `inputs_wo` and `state_output` keys is written to the standard input.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inputModeValues...),
+				},
+			},
+			"inputs_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the inputs are validated against before any command runs; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the `inputs` are validated against before any command runs; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
+			"inputs": schema.DynamicAttribute{
+				Description:         "Inputs to be made available to the command.",
+				MarkdownDescription: "Inputs to be made available to the command; these can be accessed as JSON via the `TF_SCRIPT_INPUTS` environment variable.",
+				Optional:            true,
+			},
+			"os_commands": schema.MapNestedAttribute{
+				Description:         "A map of commands to run when the action is invoked where the map key is the GOOS value or default; default must be provided.",
+				MarkdownDescription: "A map of commands to run when the action is invoked where the map key is the `GOOS` value or `default`; `default` must be provided.",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"invoke": schema.SingleNestedAttribute{
+							MarkdownDescription: "The invoke command configuration.",
+							Required:            true,
+							Attributes: map[string]schema.Attribute{
+								"interpreter": schema.ListAttribute{
+									MarkdownDescription: "The interpreter to use for executing the invoke command; if not set the provider default interpreter will be used.",
+									ElementType:         types.StringType,
+									Optional:            true,
+									Validators: []validator.List{
+										listvalidator.SizeAtLeast(1),
+									},
+								},
+								"command": schema.StringAttribute{
+									MarkdownDescription: "The invoke command to execute.",
+									Required:            true,
+								},
+							},
+						},
+					},
+				},
+			},
+			"timeouts": timeouts.AttributesWithOpts(ctx, timeouts.Opts{
+				InvokeDescription: "Timeout for invoking the action; this defaults to the provider create value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) consisting of numbers and unit suffixes, such as `30s` or `2h45m`. Valid time units are `s` (seconds), `m` (minutes), `h` (hours).",
+			}),
+		},
+	}
+}
+
+// Configure configures the action.
+func (a *CommandAction) Configure(_ context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ShellProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected action provider data.", fmt.Sprintf("expected *ShellProviderData, got: %T", req.ProviderData))
+		return
+	}
+
+	a.providerData = providerData
+}
+
+// ValidateConfig validates the action config.
+func (a *CommandAction) ValidateConfig(ctx context.Context, req action.ValidateConfigRequest, resp *action.ValidateConfigResponse) {
+	var conf CommandActionModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &conf)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var commands map[string]InvokeCommandModel
+	if resp.Diagnostics.Append(conf.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, ok := commands[defaultCommandsKey]
+	if !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("os_commands").AtMapKey(defaultCommandsKey), "Default commands are required.", "expected default to be set in os_commands")
+		return
+	}
+
+	resp.Diagnostics.Append(validateInputs(ctx, conf.InputsSchema, conf.Inputs)...)
+}
+
+// Invoke invokes the action.
+func (a *CommandAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data CommandActionModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var commands map[string]InvokeCommandModel
+	if resp.Diagnostics.Append(data.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var command InvokeCommandModel
+	command, ok := commands[runtime.GOOS]
+	if !ok {
+		command = commands[defaultCommandsKey]
+	}
+
+	timeout, diags := data.Timeouts.Invoke(ctx, a.providerData.DefaultTimeouts.Create)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if resp.Diagnostics.Append(validateInputs(ctx, data.InputsSchema, data.Inputs)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := resolveRunOptions(ctx, a.providerData, runConfig{
+		Environment:            data.Environment,
+		InheritEnvironment:     data.InheritEnvironment,
+		EnvironmentPassthrough: data.EnvironmentPassthrough,
+		WorkingDirectory:       data.WorkingDirectory,
+		InputMode:              data.InputMode,
+		Inputs:                 data.Inputs,
+	}, command.Invoke)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+	opts.Lifecycle = script.LifecycleInvoke
+	opts.ReadJSON = false
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	runner := script.NewCommandRunner(&shell.LogProvider{
+		Logger: &script.ProgressLogger{
+			Progress: func(msg string) {
+				resp.SendProgress(action.InvokeProgressEvent{Message: msg})
+			},
+		},
+	})
+
+	_, diags = runner.Run(ctx, opts)
+	resp.Diagnostics.Append(diags...)
+}
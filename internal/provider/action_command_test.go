@@ -0,0 +1,219 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccCommandAction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoke", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_14_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+action "shell_command" "test" {
+  config {
+    inputs = {
+      path = %q
+    }
+    os_commands = {
+      default = {
+        invoke = {
+          command = <<-EOF
+            set -euo pipefail
+            path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+            printf '%%s' "$${TF_SCRIPT_LIFECYCLE}" > "$${path}"
+          EOF
+        }
+      }
+      windows = {
+        invoke = {
+          command = <<-EOF
+            $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+            [IO.File]::WriteAllText($inputs.path, $env:TF_SCRIPT_LIFECYCLE)
+          EOF
+        }
+      }
+    }
+  }
+}
+
+resource "terraform_data" "test" {
+  lifecycle {
+    action_trigger {
+      events  = [after_create]
+      actions = [action.shell_command.test]
+    }
+  }
+}
+`, file),
+					Check: func(_ *terraform.State) error {
+						by, err := os.ReadFile(file)
+						if err != nil {
+							return fmt.Errorf("expected invoke command to have run: %w", err)
+						}
+
+						if string(by) != "invoke" {
+							return fmt.Errorf("expected invoke lifecycle, got: %s", string(by))
+						}
+
+						return nil
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("error_no_default_commands", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_14_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: `
+action "shell_command" "test" {
+  config {
+    os_commands = {
+      linux = {
+        invoke = {
+          command = "exit 0"
+        }
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Default commands are required`),
+				},
+			},
+		})
+	})
+}
+
+func TestCommandAction_Invoke(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName     string
+		command      string
+		winCommand   string
+		wantProgress []string
+		wantError    *regexp.Regexp
+	}{
+		{
+			testName:     "progress",
+			command:      `printf '[INFO] starting\nignored\n[WARN] careful\n'`,
+			winCommand:   `Write-Output '[INFO] starting'; Write-Output 'ignored'; Write-Output '[WARN] careful'`,
+			wantProgress: []string{"starting", "careful"},
+		},
+		{
+			testName:   "error_message",
+			command:    `printf 'my-error' > "${TF_SCRIPT_ERROR}"; exit 1`,
+			winCommand: `'my-error' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8; exit 1`,
+			wantError:  regexp.MustCompile(`my-error`),
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+
+			command := d.command
+			if runtime.GOOS == "windows" {
+				command = d.winCommand
+			}
+
+			a := NewCommandAction().(*CommandAction)
+			a.providerData = &ShellProviderData{
+				DefaultInterpreter: defaultInterpreter(),
+				DefaultTimeouts:    &Timeouts{Create: 10 * time.Minute},
+			}
+
+			schemaResp := &action.SchemaResponse{}
+			a.Schema(ctx, action.SchemaRequest{}, schemaResp)
+
+			typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+			commandsType := typ.AttributeTypes["os_commands"].(tftypes.Map)
+			invokeType := commandsType.ElementType.(tftypes.Object)
+			commandType := invokeType.AttributeTypes["invoke"].(tftypes.Object)
+
+			attrs := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+			for k, v := range typ.AttributeTypes {
+				attrs[k] = tftypes.NewValue(v, nil)
+			}
+			attrs["os_commands"] = tftypes.NewValue(commandsType, map[string]tftypes.Value{
+				defaultCommandsKey: tftypes.NewValue(invokeType, map[string]tftypes.Value{
+					"invoke": tftypes.NewValue(commandType, map[string]tftypes.Value{
+						"interpreter": tftypes.NewValue(commandType.AttributeTypes["interpreter"], nil),
+						"command":     tftypes.NewValue(tftypes.String, command),
+					}),
+				}),
+			})
+
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(typ, attrs),
+			}
+
+			var progress []string
+			resp := &action.InvokeResponse{
+				SendProgress: func(event action.InvokeProgressEvent) {
+					progress = append(progress, event.Message)
+				},
+			}
+			a.Invoke(ctx, action.InvokeRequest{Config: config}, resp)
+
+			if d.wantError != nil {
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("expected error")
+				}
+
+				if !d.wantError.MatchString(resp.Diagnostics.Errors()[0].Detail()) {
+					t.Errorf("unexpected error detail: %s", resp.Diagnostics.Errors()[0].Detail())
+				}
+				return
+			}
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics.Errors())
+			}
+
+			if diff := cmp.Diff(d.wantProgress, progress); diff != "" {
+				t.Errorf("Invoke() progress mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
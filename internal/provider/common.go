@@ -1,13 +1,142 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"maps"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
+	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
 )
 
+// inheritEnvironmentValues are the valid values for the inherit_environment attributes.
+var inheritEnvironmentValues = []string{
+	string(shell.EnvironmentModeAll),
+	string(shell.EnvironmentModeNone),
+	string(shell.EnvironmentModeAllowlist),
+}
+
+// inputModeValues are the valid values for the input_mode attributes.
+var inputModeValues = []string{
+	string(script.InputModeEnv),
+	string(script.InputModeFile),
+	string(script.InputModeStdin),
+}
+
+// outputSourceValues are the valid values for the output_source attributes.
+var outputSourceValues = []string{
+	string(script.OutputSourceFile),
+	string(script.OutputSourceStdout),
+}
+
+// outputFormatValues are the valid values for the output_format attributes.
+var outputFormatValues = []string{
+	string(shell.OutputFormatJSON),
+	string(shell.OutputFormatYAML),
+	string(shell.OutputFormatTOML),
+	string(shell.OutputFormatDotenv),
+	string(shell.OutputFormatRaw),
+}
+
+// outputCollectionModeValues are the valid values for the output_collection_mode attributes.
+var outputCollectionModeValues = []string{
+	string(tfdynamic.CollectionModeStructural),
+	string(tfdynamic.CollectionModeInfer),
+}
+
+// defaultTimeout is the timeout for commands if not configured.
+const defaultTimeout = 10 * time.Minute
+
+// defaultTerminationGracePeriod is the time to wait for a terminated command to exit before it is killed if not
+// configured.
+const defaultTerminationGracePeriod = 10 * time.Second
+
+// defaultRetryPolicy is the provider retry policy if not configured; commands are only run once.
+var defaultRetryPolicy = script.RetryPolicy{
+	MaxAttempts:    1,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+}
+
+// RetryModel describes the retry data model.
+type RetryModel struct {
+	MaxAttempts           types.Int64   `tfsdk:"max_attempts"`
+	InitialBackoff        types.String  `tfsdk:"initial_backoff"`
+	MaxBackoff            types.String  `tfsdk:"max_backoff"`
+	Multiplier            types.Float64 `tfsdk:"multiplier"`
+	Jitter                types.Float64 `tfsdk:"jitter"`
+	RetryableExitCodes    types.List    `tfsdk:"retryable_exit_codes"`
+	RetryableErrorPattern types.String  `tfsdk:"retryable_error_pattern"`
+}
+
+// defaultInterpreter returns the platform default interpreter.
+func defaultInterpreter() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"pwsh", "-c"}
+	}
+
+	return []string{"/bin/bash", "-c"}
+}
+
+// resolveDefaultInterpreter resolves the provider default interpreter from the OS interpreters, the interpreter or the
+// platform default in that order; a configured interpreter must be an executable in PATH.
+func resolveDefaultInterpreter(ctx context.Context, tfInterpreter types.List, tfOSInterpreters types.Map) ([]string, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if !tfOSInterpreters.IsNull() && !tfOSInterpreters.IsUnknown() {
+		osInterpreters := map[string][]string{}
+		if diags.Append(tfOSInterpreters.ElementsAs(ctx, &osInterpreters, false)...); diags.HasError() {
+			return nil, diags
+		}
+
+		if interpreter, ok := osInterpreters[runtime.GOOS]; ok {
+			diags.Append(validateInterpreter(path.Root("os_interpreters").AtMapKey(runtime.GOOS), interpreter)...)
+			return interpreter, diags
+		}
+	}
+
+	if !tfInterpreter.IsNull() && !tfInterpreter.IsUnknown() {
+		var interpreter []string
+		if diags.Append(tfInterpreter.ElementsAs(ctx, &interpreter, false)...); diags.HasError() {
+			return nil, diags
+		}
+
+		diags.Append(validateInterpreter(path.Root("interpreter"), interpreter)...)
+		return interpreter, diags
+	}
+
+	return defaultInterpreter(), diags
+}
+
+// validateInterpreter validates that the interpreter executable exists in PATH.
+func validateInterpreter(p path.Path, interpreter []string) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	if len(interpreter) == 0 {
+		diags.AddAttributeError(p, "Invalid interpreter.", "expected the interpreter to contain at least one element")
+		return diags
+	}
+
+	if _, err := exec.LookPath(interpreter[0]); err != nil {
+		diags.AddAttributeError(p, "Interpreter not found.", fmt.Sprintf("expected %q to be an executable in PATH: %s", interpreter[0], err.Error()))
+	}
+
+	return diags
+}
+
 // resolveInterpreter resolves the interpreter from the TF type or falls back to the default.
 func resolveInterpreter(ctx context.Context, tfInterpreter types.List, defaultInterpreter []string) ([]string, diag.Diagnostics) {
 	if !tfInterpreter.IsNull() {
@@ -37,3 +166,411 @@ func resolveEnvironment(ctx context.Context, tfEnvironment types.Map, defaultEnv
 
 	return environment, diags
 }
+
+// resolveInheritEnvironment resolves the OS environment inheritance from the TF types or falls back to the default for
+// each value that isn't set.
+func resolveInheritEnvironment(ctx context.Context, tfMode types.String, tfPassthrough types.List, defaultInherit shell.InheritEnvironment) (shell.InheritEnvironment, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	inherit := defaultInherit
+
+	if !tfMode.IsNull() && !tfMode.IsUnknown() {
+		inherit.Mode = shell.EnvironmentMode(tfMode.ValueString())
+	}
+
+	if !tfPassthrough.IsNull() && !tfPassthrough.IsUnknown() {
+		var passthrough []string
+		if diags.Append(tfPassthrough.ElementsAs(ctx, &passthrough, false)...); diags.HasError() {
+			return inherit, diags
+		}
+
+		for i, pattern := range passthrough {
+			if err := shell.ValidateEnvironmentPattern(pattern); err != nil {
+				diags.AddAttributeError(path.Root("environment_passthrough").AtListIndex(i), "Invalid environment passthrough pattern.", fmt.Sprintf("expected a valid glob pattern: %s", err.Error()))
+			}
+		}
+		if diags.HasError() {
+			return inherit, diags
+		}
+
+		inherit.Passthrough = passthrough
+	}
+
+	return inherit, diags
+}
+
+// resolveRetryPolicy resolves the retry policy from the TF object or falls back to the default for each value that
+// isn't set.
+func resolveRetryPolicy(ctx context.Context, tfRetry types.Object, defaultRetry script.RetryPolicy) (script.RetryPolicy, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	retry := defaultRetry
+
+	if tfRetry.IsNull() || tfRetry.IsUnknown() {
+		return retry, diags
+	}
+
+	var model RetryModel
+	if diags.Append(tfRetry.As(ctx, &model, basetypes.ObjectAsOptions{UnhandledUnknownAsEmpty: true})...); diags.HasError() {
+		return retry, diags
+	}
+
+	if !model.MaxAttempts.IsNull() && !model.MaxAttempts.IsUnknown() {
+		retry.MaxAttempts = int(model.MaxAttempts.ValueInt64())
+	}
+
+	if !model.InitialBackoff.IsNull() && !model.InitialBackoff.IsUnknown() {
+		d, err := time.ParseDuration(model.InitialBackoff.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("retry").AtName("initial_backoff"), "Invalid retry backoff.", err.Error())
+		}
+		retry.InitialBackoff = d
+	}
+
+	if !model.MaxBackoff.IsNull() && !model.MaxBackoff.IsUnknown() {
+		d, err := time.ParseDuration(model.MaxBackoff.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("retry").AtName("max_backoff"), "Invalid retry backoff.", err.Error())
+		}
+		retry.MaxBackoff = d
+	}
+
+	if !model.Multiplier.IsNull() && !model.Multiplier.IsUnknown() {
+		retry.Multiplier = model.Multiplier.ValueFloat64()
+	}
+
+	if !model.Jitter.IsNull() && !model.Jitter.IsUnknown() {
+		retry.Jitter = model.Jitter.ValueFloat64()
+	}
+
+	if !model.RetryableExitCodes.IsNull() && !model.RetryableExitCodes.IsUnknown() {
+		var codes []int64
+		if diags.Append(model.RetryableExitCodes.ElementsAs(ctx, &codes, false)...); diags.HasError() {
+			return retry, diags
+		}
+
+		retry.RetryableExitCodes = make([]int, 0, len(codes))
+		for _, c := range codes {
+			retry.RetryableExitCodes = append(retry.RetryableExitCodes, int(c))
+		}
+	}
+
+	if !model.RetryableErrorPattern.IsNull() && !model.RetryableErrorPattern.IsUnknown() {
+		re, err := regexp.Compile(model.RetryableErrorPattern.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("retry").AtName("retryable_error_pattern"), "Invalid retry error pattern.", fmt.Sprintf("expected a valid regular expression: %s", err.Error()))
+		}
+		retry.ErrorPattern = re
+	}
+
+	if retry.MaxBackoff > 0 && retry.InitialBackoff > retry.MaxBackoff {
+		diags.AddAttributeError(path.Root("retry"), "Invalid retry backoff.", fmt.Sprintf("initial_backoff %s can't be greater than max_backoff %s", retry.InitialBackoff, retry.MaxBackoff))
+	}
+
+	return retry, diags
+}
+
+// resolveTerminationGracePeriod resolves the termination grace period from the TF type or falls back to the default.
+func resolveTerminationGracePeriod(tfGracePeriod types.String, defaultGracePeriod time.Duration) (time.Duration, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	if tfGracePeriod.IsNull() || tfGracePeriod.IsUnknown() {
+		return defaultGracePeriod, diags
+	}
+
+	gracePeriod, err := time.ParseDuration(tfGracePeriod.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("termination_grace_period"), "Invalid termination grace period.", err.Error())
+		return defaultGracePeriod, diags
+	}
+
+	if gracePeriod < 0 {
+		diags.AddAttributeError(path.Root("termination_grace_period"), "Invalid termination grace period.", fmt.Sprintf("expected a non-negative duration, got: %s", gracePeriod))
+		return defaultGracePeriod, diags
+	}
+
+	return gracePeriod, diags
+}
+
+// runConfig describes the configuration shared by the commands; attributes which aren't in a schema are left null.
+type runConfig struct {
+	Environment            types.Map
+	SensitiveEnvironment   types.Map
+	InheritEnvironment     types.String
+	EnvironmentPassthrough types.List
+	WorkingDirectory       types.String
+	InputMode              types.String
+	OutputSource           types.String
+	OutputFormat           types.String
+	OutputSchema           types.String
+	Inputs                 types.Dynamic
+	SensitiveInputs        types.Dynamic
+	TerminationGracePeriod types.String
+}
+
+// resolveRunOptions resolves the options to run the command from the config, falling back to the provider defaults; the
+// caller sets the lifecycle and any options specific to it.
+func resolveRunOptions(ctx context.Context, providerData *ShellProviderData, conf runConfig, command CommandModel) (script.RunOptions, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	interpreter, d := resolveInterpreter(ctx, command.Interpreter, providerData.DefaultInterpreter)
+	if diags.Append(d...); diags.HasError() {
+		return script.RunOptions{}, diags
+	}
+
+	environment, d := resolveEnvironment(ctx, conf.Environment, providerData.Environment)
+	if diags.Append(d...); diags.HasError() {
+		return script.RunOptions{}, diags
+	}
+
+	sensitiveEnvironment, d := resolveEnvironment(ctx, conf.SensitiveEnvironment, nil)
+	if diags.Append(d...); diags.HasError() {
+		return script.RunOptions{}, diags
+	}
+
+	inherit, d := resolveInheritEnvironment(ctx, conf.InheritEnvironment, conf.EnvironmentPassthrough, providerData.InheritEnvironment)
+	if diags.Append(d...); diags.HasError() {
+		return script.RunOptions{}, diags
+	}
+
+	terminationGracePeriod, d := resolveTerminationGracePeriod(conf.TerminationGracePeriod, providerData.TerminationGracePeriod)
+	if diags.Append(d...); diags.HasError() {
+		return script.RunOptions{}, diags
+	}
+
+	inputs, err := tfdynamic.EncodeDynamic(ctx, conf.Inputs)
+	if err != nil {
+		diags.AddError("Failed to encode the inputs.", err.Error())
+		return script.RunOptions{}, diags
+	}
+
+	sensitiveInputs, err := tfdynamic.EncodeDynamic(ctx, conf.SensitiveInputs)
+	if err != nil {
+		diags.AddError("Failed to encode the sensitive inputs.", err.Error())
+		return script.RunOptions{}, diags
+	}
+
+	return script.RunOptions{
+		Interpreter:            interpreter,
+		Environment:            environment,
+		SensitiveEnvironment:   sensitiveEnvironment,
+		Inherit:                inherit,
+		WorkingDirectory:       conf.WorkingDirectory.ValueString(),
+		Command:                command.Command.ValueString(),
+		Inputs:                 inputs,
+		SensitiveInputs:        sensitiveInputs,
+		InputMode:              script.InputMode(conf.InputMode.ValueString()),
+		OutputSource:           script.OutputSource(conf.OutputSource.ValueString()),
+		OutputFormat:           shell.OutputFormat(conf.OutputFormat.ValueString()),
+		OutputSchema:           conf.OutputSchema.ValueString(),
+		TerminationGracePeriod: terminationGracePeriod,
+		ReadJSON:               true,
+	}, diags
+}
+
+// privateStateGetter reads keys from the private state.
+type privateStateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// privateStateSetter writes keys to the private state.
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getPrivateValue reads a JSON value from the private state; nil is returned if the key isn't set.
+func getPrivateValue[T any](ctx context.Context, private privateStateGetter, key string) (*T, diag.Diagnostics) {
+	by, diags := private.GetKey(ctx, key)
+	if diags.HasError() || len(by) == 0 {
+		return nil, diags
+	}
+
+	// Numbers are decoded as json.Number to preserve their precision.
+	dec := json.NewDecoder(bytes.NewReader(by))
+	dec.UseNumber()
+
+	var v T
+	if err := dec.Decode(&v); err != nil {
+		diags.AddError("Failed to decode private state.", err.Error())
+		return nil, diags
+	}
+
+	return &v, diags
+}
+
+// setPrivateValue writes a value to the private state as JSON.
+func setPrivateValue(ctx context.Context, private privateStateSetter, key string, value any) diag.Diagnostics {
+	by, err := json.Marshal(value)
+	if err != nil {
+		diags := diag.Diagnostics{}
+		diags.AddError("Failed to encode private state.", err.Error())
+		return diags
+	}
+
+	return private.SetKey(ctx, key, by)
+}
+
+// jsonEqual returns true if both values have the same JSON representation.
+func jsonEqual(a, b any) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return string(ja) == string(jb), nil
+}
+
+// validateSchema validates the JSON Schema set by the attribute if it's known.
+func validateSchema(tfSchema types.String, attribute string) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	if tfSchema.IsNull() || tfSchema.IsUnknown() {
+		return diags
+	}
+
+	if _, err := script.LoadSchema(tfSchema.ValueString()); err != nil {
+		diags.AddAttributeError(path.Root(attribute), "Invalid JSON schema.", err.Error())
+	}
+
+	return diags
+}
+
+// validateInputs validates the inputs against the inputs schema; inputs which aren't fully known are skipped as they're
+// validated again once they're known.
+func validateInputs(ctx context.Context, tfSchema types.String, tfInputs types.Dynamic) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	if tfSchema.IsNull() || tfSchema.IsUnknown() {
+		return diags
+	}
+
+	tv, err := tfInputs.ToTerraformValue(ctx)
+	if err != nil {
+		diags.AddError("Failed to read the inputs.", err.Error())
+		return diags
+	}
+
+	if !tv.IsFullyKnown() {
+		return diags
+	}
+
+	sch, err := script.LoadSchema(tfSchema.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("inputs_schema"), "Invalid JSON schema.", err.Error())
+		return diags
+	}
+
+	inputs, err := tfdynamic.EncodeDynamic(ctx, tfInputs)
+	if err != nil {
+		diags.AddError("Failed to encode the inputs.", err.Error())
+		return diags
+	}
+
+	violations, err := sch.Validate(inputs)
+	if err != nil {
+		diags.AddError("Failed to validate the inputs.", err.Error())
+		return diags
+	}
+
+	for _, v := range violations {
+		diags.AddAttributeError(v.AttributePath("inputs"), "Inputs don't match the inputs schema.", fmt.Sprintf("%s: %s", v.Path("inputs"), v.Message))
+	}
+
+	return diags
+}
+
+// validateOutputType validates the output type constraint if it's known.
+func validateOutputType(tfOutputType types.String) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	if tfOutputType.IsNull() || tfOutputType.IsUnknown() {
+		return diags
+	}
+
+	if err := tfdynamic.ValidateType(tfOutputType.ValueString()); err != nil {
+		diags.AddAttributeError(path.Root("output_type"), "Invalid output type.", err.Error())
+	}
+
+	return diags
+}
+
+// outputDecodeOptions returns the options used to decode the output.
+func outputDecodeOptions(providerData *ShellProviderData, collectionMode, outputType types.String) tfdynamic.DecodeOptions {
+	return tfdynamic.DecodeOptions{
+		CollectionMode:              tfdynamic.CollectionMode(collectionMode.ValueString()),
+		Type:                        outputType.ValueString(),
+		DisableUnknownStringLiteral: !providerData.UnknownStringLiteral,
+	}
+}
+
+// decodeOutput decodes the script output into the output and sensitive output values; if sensitiveOutput is true the
+// whole output is sensitive, otherwise only the values at the sensitive paths are.
+func decodeOutput(ctx context.Context, output any, sensitiveOutput types.Bool, sensitivePaths []string, opts tfdynamic.DecodeOptions) (types.Dynamic, types.Dynamic, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if len(opts.Type) > 0 && !sensitiveOutput.ValueBool() && len(sensitivePaths) > 0 {
+		diags.AddError("Invalid sensitive paths.", "sensitive paths can't be used with output_type; use sensitive_output instead")
+		return types.DynamicNull(), types.DynamicNull(), diags
+	}
+
+	visible, sensitive, err := splitSensitiveOutput(output, sensitiveOutput.ValueBool(), sensitivePaths)
+	if err != nil {
+		diags.AddError("Invalid sensitive paths.", err.Error())
+		return types.DynamicNull(), types.DynamicNull(), diags
+	}
+
+	out, d := tfdynamic.DecodeWithOptions(ctx, visible, opts)
+	if diags.Append(d...); diags.HasError() {
+		return types.DynamicNull(), types.DynamicNull(), diags
+	}
+
+	sensitiveOut, d := tfdynamic.DecodeWithOptions(ctx, sensitive, opts)
+	if diags.Append(d...); diags.HasError() {
+		return types.DynamicNull(), types.DynamicNull(), diags
+	}
+
+	return out, sensitiveOut, diags
+}
+
+// encodeStateOutput encodes the output and sensitive output values and merges them back into the script output.
+func encodeStateOutput(ctx context.Context, output, sensitiveOutput types.Dynamic) (any, error) {
+	visible, err := tfdynamic.EncodeDynamic(ctx, output)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive, err := tfdynamic.EncodeDynamic(ctx, sensitiveOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeSensitiveOutput(visible, sensitive), nil
+}
+
+// splitSensitiveOutput splits the output into visible and sensitive values; if all is true the whole output is
+// sensitive, otherwise the values at the sensitive paths are moved from the output object.
+func splitSensitiveOutput(output any, all bool, sensitivePaths []string) (any, any, error) {
+	if all {
+		return nil, output, nil
+	}
+
+	if len(sensitivePaths) == 0 {
+		return output, nil, nil
+	}
+
+	if _, ok := output.(map[string]any); !ok {
+		return nil, nil, fmt.Errorf("expected a JSON object output to use sensitive paths, got: %T", output)
+	}
+
+	return tfdynamic.SplitPaths(output, sensitivePaths)
+}
+
+// mergeSensitiveOutput merges the sensitive values back into the visible output.
+func mergeSensitiveOutput(visible, sensitive any) any {
+	return tfdynamic.MergePaths(visible, sensitive)
+}
@@ -1,12 +1,24 @@
 package provider
 
 import (
+	"regexp"
+	"runtime"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
 )
 
 func Test_resolveInterpreter(t *testing.T) {
@@ -62,6 +74,81 @@ func Test_resolveInterpreter(t *testing.T) {
 	}
 }
 
+func Test_resolveDefaultInterpreter(t *testing.T) {
+	t.Parallel()
+
+	shell := []string{"sh", "-c"}
+	if runtime.GOOS == "windows" {
+		shell = []string{"pwsh", "-c"}
+	}
+
+	for _, d := range []struct {
+		testName         string
+		tfInterpreter    types.List
+		tfOSInterpreters types.Map
+		want             []string
+		wantError        bool
+	}{
+		{
+			testName:         "null_uses_platform_default",
+			tfInterpreter:    types.ListNull(types.StringType),
+			tfOSInterpreters: types.MapNull(types.ListType{ElemType: types.StringType}),
+			want:             defaultInterpreter(),
+			wantError:        false,
+		},
+		{
+			testName:         "interpreter",
+			tfInterpreter:    mustStringList(t, shell),
+			tfOSInterpreters: types.MapNull(types.ListType{ElemType: types.StringType}),
+			want:             shell,
+			wantError:        false,
+		},
+		{
+			testName:         "os_interpreters_override_interpreter",
+			tfInterpreter:    mustStringList(t, []string{"missing-interpreter"}),
+			tfOSInterpreters: mustStringListMap(t, map[string][]string{runtime.GOOS: shell}),
+			want:             shell,
+			wantError:        false,
+		},
+		{
+			testName:         "os_interpreters_other_os",
+			tfInterpreter:    mustStringList(t, shell),
+			tfOSInterpreters: mustStringListMap(t, map[string][]string{"other": {"missing-interpreter"}}),
+			want:             shell,
+			wantError:        false,
+		},
+		{
+			testName:         "error_interpreter_not_found",
+			tfInterpreter:    mustStringList(t, []string{"missing-interpreter", "-c"}),
+			tfOSInterpreters: types.MapNull(types.ListType{ElemType: types.StringType}),
+			wantError:        true,
+		},
+		{
+			testName:         "error_os_interpreter_not_found",
+			tfInterpreter:    types.ListNull(types.StringType),
+			tfOSInterpreters: mustStringListMap(t, map[string][]string{runtime.GOOS: {"missing-interpreter", "-c"}}),
+			wantError:        true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			got, diags := resolveDefaultInterpreter(ctx, d.tfInterpreter, d.tfOSInterpreters)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !d.wantError {
+				if diff := cmp.Diff(d.want, got); diff != "" {
+					t.Errorf("resolveDefaultInterpreter() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
 func Test_resolveEnvironment(t *testing.T) {
 	t.Parallel()
 
@@ -134,6 +221,533 @@ func Test_resolveEnvironment(t *testing.T) {
 	}
 }
 
+func Test_resolveInheritEnvironment(t *testing.T) {
+	t.Parallel()
+
+	defaultInherit := shell.InheritEnvironment{Mode: shell.EnvironmentModeAllowlist, Passthrough: []string{"PATH"}}
+
+	for _, d := range []struct {
+		testName      string
+		tfMode        types.String
+		tfPassthrough types.List
+		want          shell.InheritEnvironment
+		wantError     bool
+	}{
+		{
+			testName:      "null_uses_default",
+			tfMode:        types.StringNull(),
+			tfPassthrough: types.ListNull(types.StringType),
+			want:          defaultInherit,
+			wantError:     false,
+		},
+		{
+			testName:      "mode_overrides_default",
+			tfMode:        types.StringValue("none"),
+			tfPassthrough: types.ListNull(types.StringType),
+			want:          shell.InheritEnvironment{Mode: shell.EnvironmentModeNone, Passthrough: []string{"PATH"}},
+			wantError:     false,
+		},
+		{
+			testName:      "passthrough_overrides_default",
+			tfMode:        types.StringNull(),
+			tfPassthrough: mustStringList(t, []string{"HOME", "AWS_*"}),
+			want:          shell.InheritEnvironment{Mode: shell.EnvironmentModeAllowlist, Passthrough: []string{"HOME", "AWS_*"}},
+			wantError:     false,
+		},
+		{
+			testName:      "unknown_uses_default",
+			tfMode:        types.StringUnknown(),
+			tfPassthrough: types.ListUnknown(types.StringType),
+			want:          defaultInherit,
+			wantError:     false,
+		},
+		{
+			testName:      "error_invalid_pattern",
+			tfMode:        types.StringNull(),
+			tfPassthrough: mustStringList(t, []string{"AWS_["}),
+			wantError:     true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			got, diags := resolveInheritEnvironment(ctx, d.tfMode, d.tfPassthrough, defaultInherit)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !d.wantError {
+				if diff := cmp.Diff(d.want, got); diff != "" {
+					t.Errorf("resolveInheritEnvironment() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_resolveRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	defaultRetry := script.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 2}
+
+	nullRetry := RetryModel{
+		MaxAttempts:           types.Int64Null(),
+		InitialBackoff:        types.StringNull(),
+		MaxBackoff:            types.StringNull(),
+		Multiplier:            types.Float64Null(),
+		Jitter:                types.Float64Null(),
+		RetryableExitCodes:    types.ListNull(types.Int64Type),
+		RetryableErrorPattern: types.StringNull(),
+	}
+
+	for _, d := range []struct {
+		testName  string
+		tfRetry   func(m RetryModel) RetryModel
+		want      script.RetryPolicy
+		wantError bool
+	}{
+		{
+			testName:  "null_uses_default",
+			tfRetry:   nil,
+			want:      defaultRetry,
+			wantError: false,
+		},
+		{
+			testName:  "null_attributes_use_default",
+			tfRetry:   func(m RetryModel) RetryModel { return m },
+			want:      defaultRetry,
+			wantError: false,
+		},
+		{
+			testName: "attributes_override_default",
+			tfRetry: func(m RetryModel) RetryModel {
+				m.MaxAttempts = types.Int64Value(5)
+				m.InitialBackoff = types.StringValue("500ms")
+				m.Jitter = types.Float64Value(0.25)
+				m.RetryableExitCodes = types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(75)})
+				m.RetryableErrorPattern = types.StringValue("timeout")
+				return m
+			},
+			want: script.RetryPolicy{
+				MaxAttempts:        5,
+				InitialBackoff:     500 * time.Millisecond,
+				MaxBackoff:         time.Minute,
+				Multiplier:         2,
+				Jitter:             0.25,
+				RetryableExitCodes: []int{75},
+				ErrorPattern:       regexp.MustCompile("timeout"),
+			},
+			wantError: false,
+		},
+		{
+			testName: "error_invalid_backoff",
+			tfRetry: func(m RetryModel) RetryModel {
+				m.MaxBackoff = types.StringValue("soon")
+				return m
+			},
+			wantError: true,
+		},
+		{
+			testName: "error_initial_backoff_greater_than_max_backoff",
+			tfRetry: func(m RetryModel) RetryModel {
+				m.InitialBackoff = types.StringValue("2m")
+				return m
+			},
+			wantError: true,
+		},
+		{
+			testName: "error_invalid_pattern",
+			tfRetry: func(m RetryModel) RetryModel {
+				m.RetryableErrorPattern = types.StringValue("[")
+				return m
+			},
+			wantError: true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			tfRetry := types.ObjectNull(mustRetryObject(t, nullRetry).AttributeTypes(t.Context()))
+			if d.tfRetry != nil {
+				tfRetry = mustRetryObject(t, d.tfRetry(nullRetry))
+			}
+
+			ctx := t.Context()
+			got, diags := resolveRetryPolicy(ctx, tfRetry, defaultRetry)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !d.wantError {
+				regexpComparer := cmp.Comparer(func(a, b *regexp.Regexp) bool {
+					if a == nil || b == nil {
+						return a == b
+					}
+					return a.String() == b.String()
+				})
+				if diff := cmp.Diff(d.want, got, regexpComparer); diff != "" {
+					t.Errorf("resolveRetryPolicy() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_resolveTerminationGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName      string
+		tfGracePeriod types.String
+		want          time.Duration
+		wantError     bool
+	}{
+		{
+			testName:      "null_uses_default",
+			tfGracePeriod: types.StringNull(),
+			want:          10 * time.Second,
+			wantError:     false,
+		},
+		{
+			testName:      "unknown_uses_default",
+			tfGracePeriod: types.StringUnknown(),
+			want:          10 * time.Second,
+			wantError:     false,
+		},
+		{
+			testName:      "value_overrides_default",
+			tfGracePeriod: types.StringValue("1m30s"),
+			want:          90 * time.Second,
+			wantError:     false,
+		},
+		{
+			testName:      "error_invalid_duration",
+			tfGracePeriod: types.StringValue("soon"),
+			wantError:     true,
+		},
+		{
+			testName:      "error_negative_duration",
+			tfGracePeriod: types.StringValue("-1s"),
+			wantError:     true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := resolveTerminationGracePeriod(d.tfGracePeriod, 10*time.Second)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !d.wantError && got != d.want {
+				t.Errorf("resolveTerminationGracePeriod() = %v, want %v", got, d.want)
+			}
+		})
+	}
+}
+
+func Test_resolveRunOptions(t *testing.T) {
+	t.Parallel()
+
+	providerData := &ShellProviderData{
+		DefaultInterpreter:     []string{"/bin/sh", "-c"},
+		Environment:            map[string]string{"A": "provider", "B": "provider"},
+		InheritEnvironment:     shell.InheritEnvironment{Mode: shell.EnvironmentModeNone},
+		TerminationGracePeriod: 10 * time.Second,
+	}
+
+	for _, d := range []struct {
+		testName  string
+		conf      runConfig
+		command   CommandModel
+		want      script.RunOptions
+		wantError bool
+	}{
+		{
+			testName: "null_uses_defaults",
+			conf:     runConfig{},
+			command:  CommandModel{Interpreter: types.ListNull(types.StringType), Command: types.StringValue("true")},
+			want: script.RunOptions{
+				Interpreter:            []string{"/bin/sh", "-c"},
+				Environment:            map[string]string{"A": "provider", "B": "provider"},
+				SensitiveEnvironment:   map[string]string{},
+				Inherit:                shell.InheritEnvironment{Mode: shell.EnvironmentModeNone},
+				Command:                "true",
+				TerminationGracePeriod: 10 * time.Second,
+				ReadJSON:               true,
+			},
+			wantError: false,
+		},
+		{
+			testName: "config_overrides_defaults",
+			conf: runConfig{
+				Environment:            types.MapValueMust(types.StringType, map[string]attr.Value{"B": types.StringValue("config")}),
+				SensitiveEnvironment:   types.MapValueMust(types.StringType, map[string]attr.Value{"C": types.StringValue("secret")}),
+				InheritEnvironment:     types.StringValue("all"),
+				WorkingDirectory:       types.StringValue("/tmp"),
+				InputMode:              types.StringValue("stdin"),
+				OutputSource:           types.StringValue("stdout"),
+				OutputFormat:           types.StringValue("yaml"),
+				Inputs:                 types.DynamicValue(types.StringValue("in")),
+				SensitiveInputs:        types.DynamicValue(types.StringValue("sensitive")),
+				TerminationGracePeriod: types.StringValue("1m"),
+			},
+			command: CommandModel{Interpreter: mustStringList(t, []string{"/bin/bash", "-c"}), Command: types.StringValue("true")},
+			want: script.RunOptions{
+				Interpreter:            []string{"/bin/bash", "-c"},
+				Environment:            map[string]string{"A": "provider", "B": "config"},
+				SensitiveEnvironment:   map[string]string{"C": "secret"},
+				Inherit:                shell.InheritEnvironment{Mode: shell.EnvironmentModeAll},
+				WorkingDirectory:       "/tmp",
+				Command:                "true",
+				Inputs:                 "in",
+				SensitiveInputs:        "sensitive",
+				InputMode:              script.InputModeStdin,
+				OutputSource:           script.OutputSourceStdout,
+				OutputFormat:           shell.OutputFormatYAML,
+				TerminationGracePeriod: time.Minute,
+				ReadJSON:               true,
+			},
+			wantError: false,
+		},
+		{
+			testName:  "error_unknown_inputs",
+			conf:      runConfig{Inputs: types.DynamicUnknown()},
+			command:   CommandModel{Interpreter: types.ListNull(types.StringType), Command: types.StringValue("true")},
+			wantError: true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := resolveRunOptions(t.Context(), providerData, d.conf, d.command)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !d.wantError {
+				if diff := cmp.Diff(d.want, got); diff != "" {
+					t.Errorf("resolveRunOptions() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_changedAttributePaths(t *testing.T) {
+	t.Parallel()
+
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"environment":  tftypes.Map{ElementType: tftypes.String},
+		"inputs":       tftypes.String,
+		"output":       tftypes.String,
+		"output_drift": tftypes.Bool,
+	}}
+
+	newValue := func(region, output string, drift bool) tftypes.Value {
+		return tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"environment":  tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, nil),
+			"inputs":       tftypes.NewValue(tftypes.String, region),
+			"output":       tftypes.NewValue(tftypes.String, output),
+			"output_drift": tftypes.NewValue(tftypes.Bool, drift),
+		})
+	}
+
+	for _, d := range []struct {
+		testName string
+		state    tftypes.Value
+		plan     tftypes.Value
+		want     path.Paths
+	}{
+		{
+			testName: "unchanged",
+			state:    newValue("eu", "a", false),
+			plan:     newValue("eu", "a", false),
+			want:     nil,
+		},
+		{
+			testName: "changed_input",
+			state:    newValue("eu", "a", false),
+			plan:     newValue("us", "a", false),
+			want:     path.Paths{path.Root("inputs")},
+		},
+		{
+			testName: "changed_output_ignored",
+			state:    newValue("eu", "a", false),
+			plan:     newValue("eu", "b", true),
+			want:     nil,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := changedAttributePaths(d.state, d.plan)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if !slices.EqualFunc(got, d.want, func(a, b path.Path) bool { return a.Equal(b) }) {
+				t.Errorf("changedAttributePaths() = %v, want %v", got, d.want)
+			}
+		})
+	}
+}
+
+func Test_jsonEqual(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName  string
+		a         any
+		b         any
+		want      bool
+		wantError bool
+	}{
+		{
+			testName:  "nil",
+			a:         nil,
+			b:         nil,
+			want:      true,
+			wantError: false,
+		},
+		{
+			testName:  "equal_maps",
+			a:         map[string]any{"a": "1", "b": float64(2)},
+			b:         map[string]any{"b": float64(2), "a": "1"},
+			want:      true,
+			wantError: false,
+		},
+		{
+			testName:  "different_values",
+			a:         map[string]any{"a": "1"},
+			b:         map[string]any{"a": "2"},
+			want:      false,
+			wantError: false,
+		},
+		{
+			testName:  "nil_and_empty_map",
+			a:         nil,
+			b:         map[string]any{},
+			want:      false,
+			wantError: false,
+		},
+		{
+			testName:  "unmarshalable",
+			a:         make(chan int),
+			b:         nil,
+			want:      false,
+			wantError: true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := jsonEqual(d.a, d.b)
+
+			if (err != nil) != d.wantError {
+				t.Errorf("expected error=%v, got: %v", d.wantError, err)
+			}
+
+			if got != d.want {
+				t.Errorf("jsonEqual() = %v, want %v", got, d.want)
+			}
+		})
+	}
+}
+
+func Test_splitSensitiveOutput(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName       string
+		output         any
+		all            bool
+		sensitivePaths []string
+		wantVisible    any
+		wantSensitive  any
+		wantError      bool
+	}{
+		{
+			testName:      "no_paths",
+			output:        map[string]any{"a": "1"},
+			wantVisible:   map[string]any{"a": "1"},
+			wantSensitive: nil,
+		},
+		{
+			testName:      "all",
+			output:        map[string]any{"a": "1"},
+			all:           true,
+			wantVisible:   nil,
+			wantSensitive: map[string]any{"a": "1"},
+		},
+		{
+			testName:       "top_level_path",
+			output:         map[string]any{"a": "1", "b": "2"},
+			sensitivePaths: []string{"b"},
+			wantVisible:    map[string]any{"a": "1"},
+			wantSensitive:  map[string]any{"b": "2"},
+		},
+		{
+			testName:       "nested_path",
+			output:         map[string]any{"a": map[string]any{"b": "1", "c": "2"}},
+			sensitivePaths: []string{"a.c"},
+			wantVisible:    map[string]any{"a": map[string]any{"b": "1"}},
+			wantSensitive:  map[string]any{"a": map[string]any{"c": "2"}},
+		},
+		{
+			testName:       "list_path",
+			output:         map[string]any{"a": []any{map[string]any{"b": "1", "c": "2"}, map[string]any{"b": "3", "c": "4"}}},
+			sensitivePaths: []string{"a[1].c"},
+			wantVisible:    map[string]any{"a": []any{map[string]any{"b": "1", "c": "2"}, map[string]any{"b": "3"}}},
+			wantSensitive:  map[string]any{"a": []any{nil, map[string]any{"c": "4"}}},
+		},
+		{
+			testName:       "missing_path",
+			output:         map[string]any{"a": map[string]any{"b": "1"}},
+			sensitivePaths: []string{"c", "a.c", "a.b.c"},
+			wantVisible:    map[string]any{"a": map[string]any{"b": "1"}},
+			wantSensitive:  nil,
+		},
+		{
+			testName:       "not_an_object",
+			output:         []any{"a"},
+			sensitivePaths: []string{"a"},
+			wantError:      true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			visible, sensitive, err := splitSensitiveOutput(d.output, d.all, d.sensitivePaths)
+
+			if (err != nil) != d.wantError {
+				t.Errorf("expected error=%v, got: %v", d.wantError, err)
+			}
+
+			if diff := cmp.Diff(d.wantVisible, visible); diff != "" {
+				t.Errorf("splitSensitiveOutput() visible mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(d.wantSensitive, sensitive); diff != "" {
+				t.Errorf("splitSensitiveOutput() sensitive mismatch (-want +got):\n%s", diff)
+			}
+
+			if d.wantError {
+				return
+			}
+
+			if diff := cmp.Diff(d.output, mergeSensitiveOutput(visible, sensitive)); diff != "" {
+				t.Errorf("mergeSensitiveOutput() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestScriptResource_Configure_NilProviderData(t *testing.T) {
 	t.Parallel()
 
@@ -197,3 +811,111 @@ func TestScriptDataSource_Configure_WrongType(t *testing.T) {
 		t.Errorf("unexpected error summary: %s", resp.Diagnostics.Errors()[0].Summary())
 	}
 }
+
+func TestScriptEphemeralResource_Configure_NilProviderData(t *testing.T) {
+	t.Parallel()
+
+	e := &ScriptEphemeralResource{}
+	resp := &ephemeral.ConfigureResponse{}
+	e.Configure(t.Context(), ephemeral.ConfigureRequest{ProviderData: nil}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Errorf("expected no error for nil provider data, got: %v", resp.Diagnostics.Errors())
+	}
+
+	if e.providerData != nil {
+		t.Error("expected providerData to remain nil")
+	}
+}
+
+func TestScriptEphemeralResource_Configure_WrongType(t *testing.T) {
+	t.Parallel()
+
+	e := &ScriptEphemeralResource{}
+	resp := &ephemeral.ConfigureResponse{}
+	e.Configure(t.Context(), ephemeral.ConfigureRequest{ProviderData: "wrong-type"}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error for wrong provider data type")
+	}
+
+	if resp.Diagnostics.Errors()[0].Summary() != "Unexpected ephemeral resource provider data." {
+		t.Errorf("unexpected error summary: %s", resp.Diagnostics.Errors()[0].Summary())
+	}
+}
+
+func Test_parseRenewAt(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName  string
+		renewAt   string
+		want      time.Time
+		wantError bool
+	}{
+		{
+			testName:  "empty",
+			renewAt:   "",
+			want:      time.Time{},
+			wantError: false,
+		},
+		{
+			testName:  "rfc3339",
+			renewAt:   "2025-01-02T03:04:05Z",
+			want:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			wantError: false,
+		},
+		{
+			testName:  "invalid",
+			renewAt:   "tomorrow",
+			want:      time.Time{},
+			wantError: true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, diags := parseRenewAt(d.renewAt)
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got diags: %v", d.wantError, diags.Errors())
+			}
+
+			if !got.Equal(d.want) {
+				t.Errorf("parseRenewAt() = %v, want %v", got, d.want)
+			}
+		})
+	}
+}
+
+func TestCommandAction_Configure_NilProviderData(t *testing.T) {
+	t.Parallel()
+
+	a := &CommandAction{}
+	resp := &action.ConfigureResponse{}
+	a.Configure(t.Context(), action.ConfigureRequest{ProviderData: nil}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Errorf("expected no error for nil provider data, got: %v", resp.Diagnostics.Errors())
+	}
+
+	if a.providerData != nil {
+		t.Error("expected providerData to remain nil")
+	}
+}
+
+func TestCommandAction_Configure_WrongType(t *testing.T) {
+	t.Parallel()
+
+	a := &CommandAction{}
+	resp := &action.ConfigureResponse{}
+	a.Configure(t.Context(), action.ConfigureRequest{ProviderData: "wrong-type"}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error for wrong provider data type")
+	}
+
+	if resp.Diagnostics.Errors()[0].Summary() != "Unexpected action provider data." {
+		t.Errorf("unexpected error summary: %s", resp.Diagnostics.Errors()[0].Summary())
+	}
+}
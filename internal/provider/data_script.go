@@ -6,7 +6,10 @@ import (
 	"runtime"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -15,7 +18,6 @@ import (
 
 	"github.com/terr4m/terraform-provider-shell/internal/script"
 	"github.com/terr4m/terraform-provider-shell/internal/shell"
-	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
 )
 
 var (
@@ -37,12 +39,27 @@ type ScriptDataSource struct {
 
 // ScriptDataSourceModel describes the data source data model.
 type ScriptDataSourceModel struct {
-	Environment      types.Map      `tfsdk:"environment"`
-	WorkingDirectory types.String   `tfsdk:"working_directory"`
-	Inputs           types.Dynamic  `tfsdk:"inputs"`
-	OSCommands       types.Map      `tfsdk:"os_commands"`
-	Output           types.Dynamic  `tfsdk:"output"`
-	Timeouts         timeouts.Value `tfsdk:"timeouts"`
+	Environment            types.Map      `tfsdk:"environment"`
+	SensitiveEnvironment   types.Map      `tfsdk:"sensitive_environment"`
+	InheritEnvironment     types.String   `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List     `tfsdk:"environment_passthrough"`
+	WorkingDirectory       types.String   `tfsdk:"working_directory"`
+	InputMode              types.String   `tfsdk:"input_mode"`
+	OutputSource           types.String   `tfsdk:"output_source"`
+	OutputFormat           types.String   `tfsdk:"output_format"`
+	OutputCollectionMode   types.String   `tfsdk:"output_collection_mode"`
+	OutputType             types.String   `tfsdk:"output_type"`
+	OutputSchema           types.String   `tfsdk:"output_schema"`
+	InputsSchema           types.String   `tfsdk:"inputs_schema"`
+	Inputs                 types.Dynamic  `tfsdk:"inputs"`
+	SensitiveInputs        types.Dynamic  `tfsdk:"sensitive_inputs"`
+	OSCommands             types.Map      `tfsdk:"os_commands"`
+	SensitiveOutput        types.Bool     `tfsdk:"sensitive_output"`
+	Output                 types.Dynamic  `tfsdk:"output"`
+	OutputSensitive        types.Dynamic  `tfsdk:"output_sensitive"`
+	Retry                  types.Object   `tfsdk:"retry"`
+	TerminationGracePeriod types.String   `tfsdk:"termination_grace_period"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
 }
 
 // ReadCommandModel describes a set of CRUD commands.
@@ -67,16 +84,90 @@ func (d *ScriptDataSource) Schema(ctx context.Context, _ datasource.SchemaReques
 				ElementType:         types.StringType,
 				Optional:            true,
 			},
+			"sensitive_environment": schema.MapAttribute{
+				Description:         "Sensitive environment variables to set when executing command; to be combined with the environment. Values of at least 4 characters are redacted from logged output.",
+				MarkdownDescription: "Sensitive environment variables to set when executing command; to be combined with the `environment`. Values of at least 4 characters are redacted from logged output.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"inherit_environment": schema.StringAttribute{
+				Description:         "How the OS environment is inherited by the command; this can be one of all, none or allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "How the OS environment is inherited by the command; this can be one of `all`, `none` or `allowlist`. This defaults to the provider value if not set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inheritEnvironmentValues...),
+				},
+			},
+			"environment_passthrough": schema.ListAttribute{
+				Description:         "The OS environment variable names or glob patterns to inherit when inherit_environment is allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "The OS environment variable names or glob patterns (e.g. `AWS_*`) to inherit when `inherit_environment` is `allowlist`. This defaults to the provider value if not set.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
 			"working_directory": schema.StringAttribute{
 				Description:         "The working directory to use when executing the command; this will default to the Terraform working directory.",
 				MarkdownDescription: "The working directory to use when executing the command; this will default to the _Terraform_ working directory.",
 				Optional:            true,
 			},
+			"input_mode": schema.StringAttribute{
+				Description:         "How the JSON inputs are passed to the command; this can be one of env, file or stdin. This defaults to env.",
+				MarkdownDescription: "How the JSON inputs are passed to the command; this can be one of `env`, `file` or `stdin`. This defaults to `env`, which sets the `TF_SCRIPT_*` environment variables. When set to `file` the JSON is written to temporary files with their paths in the matching `TF_SCRIPT_*_FILE` environment variables, and when set to `stdin` a single JSON document with the `lifecycle`, `inputs`, `sensitive_inputs`, `inputs_wo` and `state_output` keys is written to the standard input.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inputModeValues...),
+				},
+			},
+			"output_source": schema.StringAttribute{
+				Description:         "Where the JSON output is read from; this can be one of file or stdout. This defaults to file.",
+				MarkdownDescription: "Where the JSON output is read from; this can be one of `file` or `stdout`. This defaults to `file`, which reads the file at the path in the `TF_SCRIPT_OUTPUT` environment variable. When set to `stdout` the output is read from the standard output of the command instead, and only the standard error is logged when the provider `log_output` is enabled.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputSourceValues...),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				Description:         "The format of the output; this can be one of json, yaml, toml, dotenv or raw. This defaults to json.",
+				MarkdownDescription: "The format of the output; this can be one of `json`, `yaml`, `toml`, `dotenv` or `raw`. This defaults to `json`. The `dotenv` format reads `KEY=VALUE` lines into an object of strings and the `raw` format sets `output` to the output as a plain string with a single trailing newline removed.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormatValues...),
+				},
+			},
+			"output_collection_mode": schema.StringAttribute{
+				Description:         "How arrays and objects in the output are decoded; this can be one of structural or infer. This defaults to structural.",
+				MarkdownDescription: "How arrays and objects in the output are decoded; this can be one of `structural` or `infer`. This defaults to `structural`, which decodes arrays to tuples and objects to objects. When set to `infer` arrays and objects whose elements all have the same type are decoded to lists and maps, which can be used directly with `for_each` and collection functions; any array or object containing an unknown value is unknown during plan as its type can't be inferred.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputCollectionModeValues...),
+				},
+			},
+			"output_type": schema.StringAttribute{
+				Description:         "Terraform type constraint which the output is converted to, e.g. object({id=string, tags=optional(map(string))}).",
+				MarkdownDescription: "Terraform type constraint which the output is converted to, e.g. `object({id=string, tags=optional(map(string))})`; output which doesn't match the type is an error. Missing `optional` attributes are set to their default or `null`. When set this takes precedence over `output_collection_mode` and can't be combined with `__meta.sensitive_paths`.",
+				Optional:            true,
+			},
+			"output_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the command output is validated against; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the command output is validated against, without the `__meta` key; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
+			"inputs_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the inputs are validated against before any command runs; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the `inputs` are validated against before any command runs; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
 			"inputs": schema.DynamicAttribute{
 				Description:         "Inputs to be made available to the script.",
 				MarkdownDescription: "Inputs to be made available to the script; these can be accessed as JSON via the `TF_SCRIPT_INPUTS` environment variable.",
 				Optional:            true,
 			},
+			"sensitive_inputs": schema.DynamicAttribute{
+				Description:         "Sensitive inputs to be made available to the script.",
+				MarkdownDescription: "Sensitive inputs to be made available to the script; these can be accessed as JSON via the `TF_SCRIPT_SENSITIVE_INPUTS` environment variable. String and number values of at least 4 characters are redacted from logged output; bool values aren't redacted.",
+				Optional:            true,
+				Sensitive:           true,
+			},
 			"os_commands": schema.MapNestedAttribute{
 				Description:         "A map of commands to run as part of the Terraform lifecycle where the map key is the GOOS value or default; default must be provided.",
 				MarkdownDescription: "A map of commands to run as part of the Terraform lifecycle where the map key is the `GOOS` value or `default`; `default` must be provided.",
@@ -88,7 +179,7 @@ func (d *ScriptDataSource) Schema(ctx context.Context, _ datasource.SchemaReques
 							Required:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the read command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the read command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -104,11 +195,72 @@ func (d *ScriptDataSource) Schema(ctx context.Context, _ datasource.SchemaReques
 					},
 				},
 			},
+			"sensitive_output": schema.BoolAttribute{
+				Description:         "If true the whole script output is returned in the sensitive output_sensitive attribute instead of the output attribute.",
+				MarkdownDescription: "If `true` the whole script output is returned in the sensitive `output_sensitive` attribute instead of the `output` attribute.",
+				Optional:            true,
+			},
 			"output": schema.DynamicAttribute{
 				Description:         "The output of the script as a structured type.",
 				MarkdownDescription: "The output of the script as a structured type.",
 				Computed:            true,
 			},
+			"output_sensitive": schema.DynamicAttribute{
+				Description:         "The sensitive part of the output of the script as a structured type.",
+				MarkdownDescription: "The sensitive part of the output of the script as a structured type; this contains the whole output if `sensitive_output` is `true`, otherwise the values at any paths listed in `__meta.sensitive_paths`.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"retry": schema.SingleNestedAttribute{
+				Description:         "The retry policy for commands that exit with a non-zero code; this defaults to the provider value for each attribute that isn't set.",
+				MarkdownDescription: "The retry policy for commands that exit with a non-zero code; this defaults to the provider value for each attribute that isn't set. Each attempt is logged and retries stop once the `timeouts` deadline would be reached.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: "The maximum number of times to run a command, including the first attempt. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"initial_backoff": schema.StringAttribute{
+						MarkdownDescription: "The time to wait before the first retry. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "The maximum time to wait between retries. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+					"multiplier": schema.Float64Attribute{
+						MarkdownDescription: "The multiplier applied to the backoff after each retry. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.AtLeast(1),
+						},
+					},
+					"jitter": schema.Float64Attribute{
+						MarkdownDescription: "The maximum fraction of the backoff, between `0` and `1`, to randomly add or subtract. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.Between(0, 1),
+						},
+					},
+					"retryable_exit_codes": schema.ListAttribute{
+						MarkdownDescription: "The exit codes to retry; if neither this nor `retryable_error_pattern` are set every non-zero exit code is retried, otherwise a failure is retried if either matches. This defaults to the provider value if not set.",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+					},
+					"retryable_error_pattern": schema.StringAttribute{
+						MarkdownDescription: "A regular expression matched against the contents of the file defined by the `TF_SCRIPT_ERROR` environment variable to decide if a failure is retried. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+				},
+			},
+			"termination_grace_period": schema.StringAttribute{
+				Description:         "The time to wait for a timed out command to exit after it has been sent SIGTERM before it is killed; this defaults to the provider value if not set.",
+				MarkdownDescription: "The time to wait for a timed out or cancelled command to exit after `SIGTERM` has been sent to its process group before the group is killed with `SIGKILL`; this defaults to the provider value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `10s` or `1m`.",
+				Optional:            true,
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Read:            true,
 				ReadDescription: "Timeout for reading the data source; this defaults to the provider value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) consisting of numbers and unit suffixes, such as `30s` or `2h45m`. Valid time units are `s` (seconds), `m` (minutes), `h` (hours).",
@@ -157,6 +309,17 @@ func (d *ScriptDataSource) ValidateConfig(ctx context.Context, req datasource.Va
 		resp.Diagnostics.AddAttributeError(path.Root("os_commands").AtMapKey(defaultCommandsKey), "Default commands are required.", "expected default to be set in os_commands")
 		return
 	}
+
+	_, diags := resolveRetryPolicy(ctx, conf.Retry, script.RetryPolicy{})
+	resp.Diagnostics.Append(diags...)
+
+	_, diags = resolveTerminationGracePeriod(conf.TerminationGracePeriod, 0)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(validateOutputType(conf.OutputType)...)
+
+	resp.Diagnostics.Append(validateSchema(conf.OutputSchema, "output_schema")...)
+	resp.Diagnostics.Append(validateInputs(ctx, conf.InputsSchema, conf.Inputs)...)
 }
 
 // Read reads the data source.
@@ -182,44 +345,48 @@ func (d *ScriptDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
-	inputs, err := tfdynamic.EncodeDynamic(ctx, data.Inputs)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+	if resp.Diagnostics.Append(validateInputs(ctx, data.InputsSchema, data.Inputs)...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	interpreter, diags := resolveInterpreter(ctx, command.Read.Interpreter, d.providerData.DefaultInterpreter)
+	opts, diags := resolveRunOptions(ctx, d.providerData, runConfig{
+		Environment:            data.Environment,
+		SensitiveEnvironment:   data.SensitiveEnvironment,
+		InheritEnvironment:     data.InheritEnvironment,
+		EnvironmentPassthrough: data.EnvironmentPassthrough,
+		WorkingDirectory:       data.WorkingDirectory,
+		InputMode:              data.InputMode,
+		OutputSource:           data.OutputSource,
+		OutputFormat:           data.OutputFormat,
+		OutputSchema:           data.OutputSchema,
+		Inputs:                 data.Inputs,
+		SensitiveInputs:        data.SensitiveInputs,
+		TerminationGracePeriod: data.TerminationGracePeriod,
+	}, command.Read)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Lifecycle = script.LifecycleRead
 
-	environment, diags := resolveEnvironment(ctx, data.Environment, d.providerData.Environment)
+	opts.Retry, diags = resolveRetryPolicy(ctx, data.Retry, d.providerData.Retry)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	res, diags := d.runner.Run(ctx, script.RunOptions{
-		Interpreter:      interpreter,
-		Environment:      environment,
-		WorkingDirectory: data.WorkingDirectory.ValueString(),
-		Command:          command.Read.Command.ValueString(),
-		Lifecycle:        script.LifecycleRead,
-		Inputs:           inputs,
-		ReadJSON:         true,
-	})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := d.runner.Run(ctx, opts)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	out, diags := tfdynamic.Decode(ctx, res.Output)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	out, sensitiveOut, diags := decodeOutput(ctx, res.Output, data.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(d.providerData, data.OutputCollectionMode, data.OutputType))
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 	data.Output = out
+	data.OutputSensitive = sensitiveOut
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -2,10 +2,14 @@ package provider
 
 import (
 	"fmt"
+	"math/big"
+	"os"
+	"path"
 	"regexp"
 	"runtime"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
@@ -216,6 +220,193 @@ data "shell_script" "test" {
 		})
 	})
 
+	t.Run("read_with_inherit_environment_none", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  inherit_environment = "none"
+  environment = {
+    "MY_VALUE" = "my-value"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"value": "%s", "tf_acc": "%s"}' "$${MY_VALUE}" "$${TF_ACC:-}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          @{value=$env:MY_VALUE; tf_acc="$env:TF_ACC"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value"), "tf_acc": knownvalue.StringExact("")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_inherit_environment_allowlist", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  inherit_environment     = "allowlist"
+  environment_passthrough = ["TF_A*"]
+  environment = {
+    "MY_VALUE" = "my-value"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"value": "%s", "tf_acc": "%s"}' "$${MY_VALUE}" "$${TF_ACC:-}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          @{value=$env:MY_VALUE; tf_acc="$env:TF_ACC"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value"), "tf_acc": knownvalue.StringExact("1")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_sensitive", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  sensitive_environment = {
+    MY_SECRET = "my-secret"
+  }
+  sensitive_inputs = {
+    token = "my-token"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_SENSITIVE_INPUTS}")"
+          printf '{"secret": "%s", "token": "%s"}' "$${MY_SECRET}" "$${token}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_SENSITIVE_INPUTS | ConvertFrom-Json
+          @{secret=$env:MY_SECRET; token=$inputs.token} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"secret": knownvalue.StringExact("my-secret"), "token": knownvalue.StringExact("my-token")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_sensitive_output", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"user": "admin", "password": "my-password", "__meta": {"sensitive_paths": ["password"]}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          '{"user": "admin", "password": "my-password", "__meta": {"sensitive_paths": ["password"]}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+
+data "shell_script" "test_all" {
+  sensitive_output = true
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"password": "my-password"}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          '{"password": "my-password"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"user": knownvalue.StringExact("admin")})),
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output_sensitive"), knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-password")})),
+						statecheck.ExpectKnownValue("data.shell_script.test_all", tfjsonpath.New("output"), knownvalue.Null()),
+						statecheck.ExpectKnownValue("data.shell_script.test_all", tfjsonpath.New("output_sensitive"), knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-password")})),
+					},
+				},
+			},
+		})
+	})
+
 	t.Run("read_with_inputs", func(t *testing.T) {
 		t.Parallel()
 
@@ -226,73 +417,517 @@ data "shell_script" "test" {
 				{
 					Config: `
 data "shell_script" "test" {
-  inputs = {
-    value = "my-value"
-  }
+  inputs = {
+    value = "my-value"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_input_mode_file", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  input_mode = "file"
+  inputs = {
+    value = "my-value"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          [[ -z "$${TF_SCRIPT_INPUTS:-}" ]]
+          value="$(jq --raw-output '.value' "$${TF_SCRIPT_INPUTS_FILE}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          $inputs = Get-Content -Raw -Path $env:TF_SCRIPT_INPUTS_FILE | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_input_mode_stdin", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  input_mode = "stdin"
+  inputs = {
+    value = "my-value"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          [[ -z "$${TF_SCRIPT_INPUTS:-}" ]]
+          value="$(jq --raw-output 'select(.lifecycle == "read") | .inputs.value')"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          $inputs = ([Console]::In.ReadToEnd() | ConvertFrom-Json).inputs
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_output_source_stdout", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"data": true}'; printf '[INFO] logged\n' >&2`
+		if runtime.GOOS == "windows" {
+			cmd = `Write-Output '{"data": true}'; [Console]::Error.WriteLine('[INFO] logged')`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+provider "shell" {
+  log_output = true
+}
+
+data "shell_script" "test" {
+  output_source = "stdout"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"data": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_output_format_yaml", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf 'data: true\nitems:\n  - a\n  - b\n' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `[IO.File]::WriteAllLines($env:TF_SCRIPT_OUTPUT, @('data: true', 'items:', '  - a', '  - b'))`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_format = "yaml"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"data":  knownvalue.Bool(true),
+							"items": knownvalue.TupleExact([]knownvalue.Check{knownvalue.StringExact("a"), knownvalue.StringExact("b")}),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_output_format_raw", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf 'hello' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, 'hello')`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_format = "raw"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.StringExact("hello")),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_big_numbers", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"inputs": %s, "output": 12345678901234567890123}' "$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, '{"inputs": ' + $env:TF_SCRIPT_INPUTS + ', "output": 12345678901234567890123}')`
+		}
+
+		outputValue, _, _ := big.ParseFloat("12345678901234567890123", 10, 512, big.ToNearestEven)
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  inputs = {
+    id = 9007199254740993
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"inputs": knownvalue.ObjectExact(map[string]knownvalue.Check{"id": knownvalue.NumberExact(new(big.Float).SetInt64(9007199254740993))}),
+							"output": knownvalue.NumberExact(outputValue),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_output_collection_mode_infer", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"items": {"a": "x", "b": "y"}, "names": ["c", "d"]}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"items": {"a": "x", "b": "y"}, "names": ["c", "d"]}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_collection_mode = "infer"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+
+resource "terraform_data" "test" {
+  input = {
+    items = { for k, v in data.shell_script.test.output.items : k => upper(v) }
+    names = sort(toset(data.shell_script.test.output.names))
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("terraform_data.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"items": knownvalue.ObjectExact(map[string]knownvalue.Check{"a": knownvalue.StringExact("X"), "b": knownvalue.StringExact("Y")}),
+							"names": knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("c"), knownvalue.StringExact("d")}),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_output_type", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"id": 123, "tags": {"env": "dev"}}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"id": 123, "tags": {"env": "dev"}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_type = "object({id=string, tags=map(string), note=optional(string), names=optional(list(string), [])})"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"id":    knownvalue.StringExact("123"),
+							"tags":  knownvalue.MapExact(map[string]knownvalue.Check{"env": knownvalue.StringExact("dev")}),
+							"note":  knownvalue.Null(),
+							"names": knownvalue.ListExact([]knownvalue.Check{}),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_schemas", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"id": "abc", "port": 8080}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"id": "abc", "port": 8080}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  inputs_schema = jsonencode({
+    type     = "object"
+    required = ["name"]
+    properties = {
+      name = { type = "string", minLength = 1 }
+    }
+  })
+  output_schema = jsonencode({
+    type     = "object"
+    required = ["id", "port"]
+    properties = {
+      id   = { type = "string" }
+      port = { type = "integer", maximum = 65535 }
+    }
+  })
+  inputs = {
+    name = "test"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"id":   knownvalue.StringExact("abc"),
+							"port": knownvalue.Int64Exact(8080),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("read_with_timeout", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
   os_commands = {
     default = {
       read = {
         command = <<-EOF
           set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
-          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          sleep 1s
+          printf '{"success": true}\n' > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
     }
     windows = {
       read = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          Start-Sleep -Seconds 1
+          '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
     }
   }
+  timeouts = {
+    read = "30s"
+  }
 }
 `,
-
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value")})),
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"success": knownvalue.Bool(true)})),
 					},
 				},
 			},
 		})
 	})
 
-	t.Run("read_with_timeout", func(t *testing.T) {
+	t.Run("read_with_retry", func(t *testing.T) {
 		t.Parallel()
 
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
 		resource.Test(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 			Steps: []resource.TestStep{
 				{
-					Config: `
+					Config: fmt.Sprintf(`
 data "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
   os_commands = {
     default = {
       read = {
         command = <<-EOF
           set -euo pipefail
-          sleep 1s
-          printf '{"success": true}\n' > "$${TF_SCRIPT_OUTPUT}"
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          if [[ ! -f "$${path}" ]]; then
+            touch "$${path}"
+            printf 'service unavailable' > "$${TF_SCRIPT_ERROR}"
+            exit 75
+          fi
+          printf '{"success": true}' > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
     }
     windows = {
       read = {
         command = <<-EOF
-          Start-Sleep -Seconds 1
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          if (-not (Test-Path $inputs.path)) {
+            New-Item -ItemType File -Path $inputs.path | Out-Null
+            'service unavailable' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8
+            exit 75
+          }
           '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
     }
   }
-  timeouts = {
-    read = "30s"
+  retry = {
+    max_attempts         = 3
+    initial_backoff      = "10ms"
+    retryable_exit_codes = [75]
   }
 }
-`,
+`, file),
 					ConfigStateChecks: []statecheck.StateCheck{
 						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"success": knownvalue.Bool(true)})),
 					},
@@ -354,6 +989,171 @@ data "shell_script" "test" {
 		})
 	})
 
+	t.Run("error_output_format", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf 'FOO=bar\nBAZ\n' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `[IO.File]::WriteAllLines($env:TF_SCRIPT_OUTPUT, @('FOO=bar', 'BAZ'))`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_format = "dotenv"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ExpectError: regexp.MustCompile(`line 2, column 1: expected KEY=VALUE`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_output_type", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"tags": {"env": ["dev"]}}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"tags": {"env": ["dev"]}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_type = "object({tags=map(string)})"
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ExpectError: regexp.MustCompile(`element "env": string required`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_invalid_output_type", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  output_type = "object({id=strin})"
+  os_commands = {
+    default = {
+      read = {
+        command = "exit 0"
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Invalid output type`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_inputs_schema", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  inputs_schema = jsonencode({
+    type = "object"
+    properties = {
+      names = { type = "array", items = { type = "string", minLength = 1 } }
+    }
+  })
+  inputs = {
+    names = ["a", ""]
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = "exit 1"
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`(?s)Inputs don't match the inputs schema.*inputs\.names\[1\]: minLength`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_output_schema", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"id": 1, "port": 70000}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"id": 1, "port": 70000}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+data "shell_script" "test" {
+  output_schema = jsonencode({
+    type = "object"
+    properties = {
+      id   = { type = "string" }
+      port = { type = "integer", maximum = 65535 }
+    }
+  })
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, cmd),
+					ExpectError: regexp.MustCompile(`(?s)output.id: got number, want string.*output.port: maximum`),
+				},
+			},
+		})
+	})
+
 	t.Run("error_exit_code", func(t *testing.T) {
 		t.Parallel()
 
@@ -379,6 +1179,75 @@ data "shell_script" "test" {
 		})
 	})
 
+	t.Run("error_retry_exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf 'service unavailable' > "$${TF_SCRIPT_ERROR}"
+          exit 75
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          'service unavailable' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8
+          exit 75
+        EOF
+      }
+    }
+  }
+  retry = {
+    max_attempts            = 2
+    initial_backoff         = "10ms"
+    retryable_error_pattern = "unavailable"
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Command failed with exit code: 75`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_invalid_retry_backoff", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = "exit 0"
+      }
+    }
+  }
+  retry = {
+    initial_backoff = "soon"
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Invalid retry backoff`),
+				},
+			},
+		})
+	})
+
 	t.Run("error_timeout", func(t *testing.T) {
 		t.Parallel()
 
@@ -408,12 +1277,13 @@ data "shell_script" "test" {
       }
     }
   }
+  termination_grace_period = "1s"
   timeouts = {
     read = "1s"
   }
 }
 `,
-					ExpectError: regexp.MustCompile(".+"),
+					ExpectError: regexp.MustCompile(`Command timed out`),
 				},
 			},
 		})
@@ -454,4 +1324,43 @@ data "shell_script" "test" {
 			},
 		})
 	})
+
+	t.Run("error_diagnostics", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+data "shell_script" "test" {
+  inputs = {
+    name = "my name"
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"diagnostics": [{"severity": "error", "summary": "Invalid name.", "detail": "names cannot contain spaces", "attribute": "inputs.name"}]}' > "$${TF_SCRIPT_ERROR}"
+          exit 1
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          '{"diagnostics": [{"severity": "error", "summary": "Invalid name.", "detail": "names cannot contain spaces", "attribute": "inputs.name"}]}' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8
+          exit 1
+        EOF
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Invalid name`),
+				},
+			},
+		})
+	})
 }
@@ -0,0 +1,516 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/ephemeral/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
+	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
+)
+
+var (
+	_ ephemeral.EphemeralResource                   = &ScriptEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure      = &ScriptEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &ScriptEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithRenew          = &ScriptEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose          = &ScriptEphemeralResource{}
+)
+
+// ephemeralScriptPrivateKey is the private data key holding the data required to renew and close the ephemeral resource.
+const ephemeralScriptPrivateKey = "script"
+
+// NewScriptEphemeralResource creates a new script ephemeral resource.
+func NewScriptEphemeralResource() ephemeral.EphemeralResource {
+	return &ScriptEphemeralResource{}
+}
+
+// ScriptEphemeralResource defines the ephemeral resource implementation.
+type ScriptEphemeralResource struct {
+	providerData *ShellProviderData
+	runner       script.CommandRunner
+}
+
+// ScriptEphemeralResourceModel describes the ephemeral resource data model.
+type ScriptEphemeralResourceModel struct {
+	Environment            types.Map      `tfsdk:"environment"`
+	InheritEnvironment     types.String   `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List     `tfsdk:"environment_passthrough"`
+	WorkingDirectory       types.String   `tfsdk:"working_directory"`
+	InputMode              types.String   `tfsdk:"input_mode"`
+	OutputSource           types.String   `tfsdk:"output_source"`
+	OutputFormat           types.String   `tfsdk:"output_format"`
+	OutputCollectionMode   types.String   `tfsdk:"output_collection_mode"`
+	OutputType             types.String   `tfsdk:"output_type"`
+	OutputSchema           types.String   `tfsdk:"output_schema"`
+	InputsSchema           types.String   `tfsdk:"inputs_schema"`
+	Inputs                 types.Dynamic  `tfsdk:"inputs"`
+	OSCommands             types.Map      `tfsdk:"os_commands"`
+	Output                 types.Dynamic  `tfsdk:"output"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
+}
+
+// EphemeralCommandsModel describes a set of ephemeral commands.
+type EphemeralCommandsModel struct {
+	Open  CommandModel  `tfsdk:"open"`
+	Renew *CommandModel `tfsdk:"renew"`
+	Close *CommandModel `tfsdk:"close"`
+}
+
+// ephemeralScriptPrivate describes the data required to renew and close the ephemeral resource; the renew and close
+// requests don't have access to the configuration.
+type ephemeralScriptPrivate struct {
+	Renew                *resolvedCommand         `json:"renew,omitempty"`
+	Close                *resolvedCommand         `json:"close,omitempty"`
+	Environment          map[string]string        `json:"environment"`
+	Inherit              shell.InheritEnvironment `json:"inherit"`
+	WorkingDirectory     string                   `json:"working_directory"`
+	InputMode            script.InputMode         `json:"input_mode"`
+	OutputSource         script.OutputSource      `json:"output_source"`
+	OutputFormat         shell.OutputFormat       `json:"output_format"`
+	OutputCollectionMode string                   `json:"output_collection_mode"`
+	OutputType           string                   `json:"output_type"`
+	OutputSchema         string                   `json:"output_schema"`
+	Inputs               any                      `json:"inputs"`
+	Output               any                      `json:"output"`
+	Timeout              time.Duration            `json:"timeout"`
+}
+
+// resolvedCommand describes a command with the interpreter resolved.
+type resolvedCommand struct {
+	Interpreter []string `json:"interpreter"`
+	Command     string   `json:"command"`
+}
+
+// Metadata returns the ephemeral resource metadata.
+func (e *ScriptEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = fmt.Sprintf("%s_script", req.ProviderTypeName)
+}
+
+// Schema returns the ephemeral resource schema.
+func (e *ScriptEphemeralResource) Schema(ctx context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description:         "The Shell script ephemeral resource allows you to execute arbitrary commands to produce values that are never persisted to the Terraform plan or state.",
+		MarkdownDescription: "The _Shell_ script ephemeral resource (`shell_script`) allows you to execute arbitrary commands to produce values that are never persisted to the _Terraform_ plan or state. The open command must output a JSON string to the file defined by the `TF_SCRIPT_OUTPUT` environment variable. If a script exits with a non-zero code the provider will read any text from the file defined by the `TF_SCRIPT_ERROR` environment variable and return it as part of the error diagnostics.",
+		Attributes: map[string]schema.Attribute{
+			"environment": schema.MapAttribute{
+				Description:         "The environment variables to set when executing commands; to be combined with the OS environment and the provider environment.",
+				MarkdownDescription: "The environment variables to set when executing commands; to be combined with the OS environment and the provider environment.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"inherit_environment": schema.StringAttribute{
+				Description:         "How the OS environment is inherited by the commands; this can be one of all, none or allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "How the OS environment is inherited by the commands; this can be one of `all`, `none` or `allowlist`. This defaults to the provider value if not set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inheritEnvironmentValues...),
+				},
+			},
+			"environment_passthrough": schema.ListAttribute{
+				Description:         "The OS environment variable names or glob patterns to inherit when inherit_environment is allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "The OS environment variable names or glob patterns (e.g. `AWS_*`) to inherit when `inherit_environment` is `allowlist`. This defaults to the provider value if not set.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"working_directory": schema.StringAttribute{
+				Description:         "The working directory to use when executing the commands; this will default to the Terraform working directory.",
+				MarkdownDescription: "The working directory to use when executing the commands; this will default to the _Terraform_ working directory.",
+				Optional:            true,
+			},
+			"input_mode": schema.StringAttribute{
+				Description:         "How the JSON inputs are passed to the commands; this can be one of env, file or stdin. This defaults to env.",
+				MarkdownDescription: "How the JSON inputs are passed to the commands; this can be one of `env`, `file` or `stdin`. This defaults to `env`, which sets the `TF_SCRIPT_*` environment variables. When set to `file` the JSON is written to temporary files with their paths in the matching `TF_SCRIPT_*_FILE` environment variables, and when set to `stdin` a single JSON document with the `lifecycle`, `inputs`, `sensitive_inputs`, `inputs_wo` and `state_output` keys is written to the standard input.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inputModeValues...),
+				},
+			},
+			"output_source": schema.StringAttribute{
+				Description:         "Where the JSON output is read from; this can be one of file or stdout. This defaults to file.",
+				MarkdownDescription: "Where the JSON output is read from; this can be one of `file` or `stdout`. This defaults to `file`, which reads the file at the path in the `TF_SCRIPT_OUTPUT` environment variable. When set to `stdout` the output is read from the standard output of the commands instead, and only the standard error is logged when the provider `log_output` is enabled.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputSourceValues...),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				Description:         "The format of the output; this can be one of json, yaml, toml, dotenv or raw. This defaults to json.",
+				MarkdownDescription: "The format of the output; this can be one of `json`, `yaml`, `toml`, `dotenv` or `raw`. This defaults to `json`. The `dotenv` format reads `KEY=VALUE` lines into an object of strings and the `raw` format sets `output` to the output as a plain string with a single trailing newline removed.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormatValues...),
+				},
+			},
+			"output_collection_mode": schema.StringAttribute{
+				Description:         "How arrays and objects in the output are decoded; this can be one of structural or infer. This defaults to structural.",
+				MarkdownDescription: "How arrays and objects in the output are decoded; this can be one of `structural` or `infer`. This defaults to `structural`, which decodes arrays to tuples and objects to objects. When set to `infer` arrays and objects whose elements all have the same type are decoded to lists and maps, which can be used directly with `for_each` and collection functions; any array or object containing an unknown value is unknown during plan as its type can't be inferred.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputCollectionModeValues...),
+				},
+			},
+			"output_type": schema.StringAttribute{
+				Description:         "Terraform type constraint which the output is converted to, e.g. object({id=string, tags=optional(map(string))}).",
+				MarkdownDescription: "Terraform type constraint which the output is converted to, e.g. `object({id=string, tags=optional(map(string))})`; output which doesn't match the type is an error. Missing `optional` attributes are set to their default or `null`. When set this takes precedence over `output_collection_mode` and can't be combined with `__meta.sensitive_paths`.",
+				Optional:            true,
+			},
+			"output_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the command output is validated against; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the command output is validated against, without the `__meta` key; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
+			"inputs_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the inputs are validated against before any command runs; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the `inputs` are validated against before any command runs; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
+			"inputs": schema.DynamicAttribute{
+				Description:         "Inputs to be made available to the script.",
+				MarkdownDescription: "Inputs to be made available to the script; these can be accessed as JSON via the `TF_SCRIPT_INPUTS` environment variable.",
+				Optional:            true,
+			},
+			"os_commands": schema.MapNestedAttribute{
+				Description:         "A map of commands to run as part of the ephemeral lifecycle where the map key is the GOOS value or default; default must be provided.",
+				MarkdownDescription: "A map of commands to run as part of the ephemeral lifecycle where the map key is the `GOOS` value or `default`; `default` must be provided.",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"open": schema.SingleNestedAttribute{
+							MarkdownDescription: "The open command configuration.",
+							Required:            true,
+							Attributes: map[string]schema.Attribute{
+								"interpreter": schema.ListAttribute{
+									MarkdownDescription: "The interpreter to use for executing the open command; if not set the provider default interpreter will be used.",
+									ElementType:         types.StringType,
+									Optional:            true,
+									Validators: []validator.List{
+										listvalidator.SizeAtLeast(1),
+									},
+								},
+								"command": schema.StringAttribute{
+									MarkdownDescription: "The open command to execute.",
+									Required:            true,
+								},
+							},
+						},
+						"renew": schema.SingleNestedAttribute{
+							MarkdownDescription: "The renew command configuration; this is run at the time returned in the `__meta.renew_at` output key. The command must output JSON which will be available to subsequent renew and close commands.",
+							Optional:            true,
+							Attributes: map[string]schema.Attribute{
+								"interpreter": schema.ListAttribute{
+									MarkdownDescription: "The interpreter to use for executing the renew command; if not set the provider default interpreter will be used.",
+									ElementType:         types.StringType,
+									Optional:            true,
+									Validators: []validator.List{
+										listvalidator.SizeAtLeast(1),
+									},
+								},
+								"command": schema.StringAttribute{
+									MarkdownDescription: "The renew command to execute.",
+									Required:            true,
+								},
+							},
+						},
+						"close": schema.SingleNestedAttribute{
+							MarkdownDescription: "The close command configuration; this is run when the ephemeral resource is no longer needed.",
+							Optional:            true,
+							Attributes: map[string]schema.Attribute{
+								"interpreter": schema.ListAttribute{
+									MarkdownDescription: "The interpreter to use for executing the close command; if not set the provider default interpreter will be used.",
+									ElementType:         types.StringType,
+									Optional:            true,
+									Validators: []validator.List{
+										listvalidator.SizeAtLeast(1),
+									},
+								},
+								"command": schema.StringAttribute{
+									MarkdownDescription: "The close command to execute.",
+									Required:            true,
+								},
+							},
+						},
+					},
+				},
+			},
+			"output": schema.DynamicAttribute{
+				Description:         "The output of the open command as a structured type.",
+				MarkdownDescription: "The output of the open command as a structured type; this can be accessed in the renew and close commands as JSON via the `TF_SCRIPT_STATE_OUTPUT` environment variable.",
+				Computed:            true,
+			},
+			"timeouts": timeouts.AttributesWithOpts(ctx, timeouts.Opts{
+				OpenDescription: "Timeout for opening the ephemeral resource, this is also used for renewing and closing; this defaults to the provider read value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) consisting of numbers and unit suffixes, such as `30s` or `2h45m`. Valid time units are `s` (seconds), `m` (minutes), `h` (hours).",
+			}),
+		},
+	}
+}
+
+// Configure configures the ephemeral resource.
+func (e *ScriptEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ShellProviderData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected ephemeral resource provider data.", fmt.Sprintf("expected *ShellProviderData, got: %T", req.ProviderData))
+		return
+	}
+
+	e.providerData = providerData
+
+	var logProvider *shell.LogProvider
+	if providerData.LogOutput {
+		logProvider = &shell.LogProvider{
+			Logger: &script.TFLogLogger{},
+		}
+	}
+
+	e.runner = script.NewCommandRunner(logProvider)
+}
+
+// ValidateConfig validates the ephemeral resource config.
+func (e *ScriptEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var conf ScriptEphemeralResourceModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &conf)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var commands map[string]EphemeralCommandsModel
+	if resp.Diagnostics.Append(conf.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, ok := commands[defaultCommandsKey]
+	if !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("os_commands").AtMapKey(defaultCommandsKey), "Default commands are required.", "expected default to be set in os_commands")
+		return
+	}
+
+	resp.Diagnostics.Append(validateOutputType(conf.OutputType)...)
+
+	resp.Diagnostics.Append(validateSchema(conf.OutputSchema, "output_schema")...)
+	resp.Diagnostics.Append(validateInputs(ctx, conf.InputsSchema, conf.Inputs)...)
+}
+
+// Open opens the ephemeral resource.
+func (e *ScriptEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ScriptEphemeralResourceModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var commands map[string]EphemeralCommandsModel
+	if resp.Diagnostics.Append(data.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var command EphemeralCommandsModel
+	command, ok := commands[runtime.GOOS]
+	if !ok {
+		command = commands[defaultCommandsKey]
+	}
+
+	timeout, diags := data.Timeouts.Open(ctx, e.providerData.DefaultTimeouts.Read)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if resp.Diagnostics.Append(validateInputs(ctx, data.InputsSchema, data.Inputs)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := resolveRunOptions(ctx, e.providerData, runConfig{
+		Environment:            data.Environment,
+		InheritEnvironment:     data.InheritEnvironment,
+		EnvironmentPassthrough: data.EnvironmentPassthrough,
+		WorkingDirectory:       data.WorkingDirectory,
+		InputMode:              data.InputMode,
+		OutputSource:           data.OutputSource,
+		OutputFormat:           data.OutputFormat,
+		OutputSchema:           data.OutputSchema,
+		Inputs:                 data.Inputs,
+	}, command.Open)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+	opts.Lifecycle = script.LifecycleOpen
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := e.runner.Run(ctx, opts)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, diags := tfdynamic.DecodeWithOptions(ctx, res.Output, outputDecodeOptions(e.providerData, data.OutputCollectionMode, data.OutputType))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Output = out
+
+	private := ephemeralScriptPrivate{
+		Environment:          opts.Environment,
+		Inherit:              opts.Inherit,
+		WorkingDirectory:     opts.WorkingDirectory,
+		InputMode:            opts.InputMode,
+		OutputSource:         opts.OutputSource,
+		OutputFormat:         opts.OutputFormat,
+		OutputCollectionMode: data.OutputCollectionMode.ValueString(),
+		OutputType:           data.OutputType.ValueString(),
+		OutputSchema:         opts.OutputSchema,
+		Inputs:               opts.Inputs,
+		Output:               res.Output,
+		Timeout:              timeout,
+	}
+
+	if command.Renew != nil {
+		private.Renew, diags = resolveCommand(ctx, *command.Renew, e.providerData.DefaultInterpreter)
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.RenewAt, diags = parseRenewAt(res.Meta.RenewAt)
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if command.Close != nil {
+		private.Close, diags = resolveCommand(ctx, *command.Close, e.providerData.DefaultInterpreter)
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if resp.Diagnostics.Append(setPrivateValue(ctx, resp.Private, ephemeralScriptPrivateKey, private)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// Renew renews the ephemeral resource.
+func (e *ScriptEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	private, diags := getPrivateValue[ephemeralScriptPrivate](ctx, req.Private, ephemeralScriptPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if private == nil || private.Renew == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, private.Timeout)
+	defer cancel()
+
+	res, diags := e.runner.Run(ctx, script.RunOptions{
+		Interpreter:            private.Renew.Interpreter,
+		Environment:            private.Environment,
+		Inherit:                private.Inherit,
+		WorkingDirectory:       private.WorkingDirectory,
+		Command:                private.Renew.Command,
+		Lifecycle:              script.LifecycleRenew,
+		Inputs:                 private.Inputs,
+		StateOutput:            private.Output,
+		InputMode:              private.InputMode,
+		OutputSource:           private.OutputSource,
+		OutputFormat:           private.OutputFormat,
+		OutputSchema:           private.OutputSchema,
+		TerminationGracePeriod: e.providerData.TerminationGracePeriod,
+		ReadJSON:               true,
+	})
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The result can't be updated by a renewal, but the output is decoded to check it's valid in the same way as when
+	// the resource was opened.
+	_, diags = tfdynamic.DecodeWithOptions(ctx, res.Output, outputDecodeOptions(e.providerData, types.StringValue(private.OutputCollectionMode), types.StringValue(private.OutputType)))
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.RenewAt, diags = parseRenewAt(res.Meta.RenewAt)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	private.Output = res.Output
+
+	resp.Diagnostics.Append(setPrivateValue(ctx, resp.Private, ephemeralScriptPrivateKey, private)...)
+}
+
+// Close closes the ephemeral resource.
+func (e *ScriptEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	private, diags := getPrivateValue[ephemeralScriptPrivate](ctx, req.Private, ephemeralScriptPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if private == nil || private.Close == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, private.Timeout)
+	defer cancel()
+
+	_, diags = e.runner.Run(ctx, script.RunOptions{
+		Interpreter:            private.Close.Interpreter,
+		Environment:            private.Environment,
+		Inherit:                private.Inherit,
+		WorkingDirectory:       private.WorkingDirectory,
+		Command:                private.Close.Command,
+		Lifecycle:              script.LifecycleClose,
+		Inputs:                 private.Inputs,
+		StateOutput:            private.Output,
+		InputMode:              private.InputMode,
+		TerminationGracePeriod: e.providerData.TerminationGracePeriod,
+		ReadJSON:               false,
+	})
+	resp.Diagnostics.Append(diags...)
+}
+
+// resolveCommand resolves a command model into a command with an interpreter.
+func resolveCommand(ctx context.Context, command CommandModel, defaultInterpreter []string) (*resolvedCommand, diag.Diagnostics) {
+	interpreter, diags := resolveInterpreter(ctx, command.Interpreter, defaultInterpreter)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &resolvedCommand{
+		Interpreter: interpreter,
+		Command:     command.Command.ValueString(),
+	}, diags
+}
+
+// parseRenewAt parses the RFC 3339 renew at value; the zero time is returned if the value isn't set.
+func parseRenewAt(renewAt string) (time.Time, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if renewAt == "" {
+		return time.Time{}, diags
+	}
+
+	t, err := time.Parse(time.RFC3339, renewAt)
+	if err != nil {
+		diags.AddError("Invalid renew_at value.", fmt.Sprintf("expected an RFC 3339 timestamp: %s", err.Error()))
+		return time.Time{}, diags
+	}
+
+	return t, diags
+}
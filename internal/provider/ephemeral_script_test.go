@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccScriptEphemeralResource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("open", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"token": "secret"}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"token": "secret"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_10_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+ephemeral "shell_script" "test" {
+  os_commands = {
+    default = {
+      open = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+
+provider "echo" {
+  data = ephemeral.shell_script.test.output
+}
+
+resource "echo" "test" {}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.ObjectExact(map[string]knownvalue.Check{"token": knownvalue.StringExact("secret")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("open_with_inputs", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_10_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: `
+ephemeral "shell_script" "test" {
+  inputs = {
+    value = "my-value"
+  }
+  os_commands = {
+    default = {
+      open = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      open = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+
+provider "echo" {
+  data = ephemeral.shell_script.test.output
+}
+
+resource "echo" "test" {}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact("my-value")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_10_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+ephemeral "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  os_commands = {
+    default = {
+      open = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"open": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      close = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '%%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      open = {
+        command = <<-EOF
+          '{"open": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      close = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::WriteAllText($inputs.path, $env:TF_SCRIPT_STATE_OUTPUT)
+        EOF
+      }
+    }
+  }
+}
+
+provider "echo" {
+  data = ephemeral.shell_script.test.output
+}
+
+resource "echo" "test" {}
+`, file),
+					Check: func(_ *terraform.State) error {
+						by, err := os.ReadFile(file)
+						if err != nil {
+							return fmt.Errorf("expected close command to have run: %w", err)
+						}
+
+						if !regexp.MustCompile(`"open":\s*true`).Match(by) {
+							return fmt.Errorf("expected close command to receive the output, got: %s", string(by))
+						}
+
+						return nil
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("error_no_default_commands", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_10_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: `
+ephemeral "shell_script" "test" {
+  os_commands = {
+    linux = {
+      open = {
+        command = "exit 1"
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Default commands are required`),
+				},
+			},
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
+)
+
+var _ function.Function = &ExecFunction{}
+
+// NewExecFunction creates a new exec function.
+func NewExecFunction() function.Function {
+	return &ExecFunction{
+		runner: script.NewCommandRunner(nil),
+	}
+}
+
+// ExecFunction defines the function implementation.
+type ExecFunction struct {
+	runner script.CommandRunner
+}
+
+// Metadata returns the function metadata.
+func (f *ExecFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "exec"
+}
+
+// Definition returns the function definition.
+func (f *ExecFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Execute a command and return its output.",
+		Description:         "Executes a command with the platform default interpreter and returns the JSON written to the file defined by the TF_SCRIPT_OUTPUT environment variable as a structured type.",
+		MarkdownDescription: "Executes a command with the platform default interpreter (`/bin/bash -c` or `pwsh -c` on _Windows_) and returns the JSON written to the file defined by the `TF_SCRIPT_OUTPUT` environment variable as a structured type. If the command exits with a non-zero code any text written to the file defined by the `TF_SCRIPT_ERROR` environment variable will be returned as part of the error. The command is stopped if it doesn't complete within the default provider timeout of `10m`. The provider configuration, including the default interpreter, timeouts and `unknown_string_literal`, doesn't apply to functions; as functions must return known values a `???` string is returned as is.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "command",
+				Description:         "The command to execute.",
+				MarkdownDescription: "The command to execute.",
+			},
+			function.DynamicParameter{
+				Name:                "inputs",
+				Description:         "Inputs to be made available to the command.",
+				MarkdownDescription: "Inputs to be made available to the command; these can be accessed as JSON via the `TF_SCRIPT_INPUTS` environment variable.",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+// Run runs the function.
+func (f *ExecFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var command string
+	var tfInputs types.Dynamic
+
+	if resp.Error = req.Arguments.Get(ctx, &command, &tfInputs); resp.Error != nil {
+		return
+	}
+
+	inputs, err := tfdynamic.EncodeDynamic(ctx, tfInputs)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Failed to encode the inputs: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	res, diags := f.runner.Run(ctx, script.RunOptions{
+		Interpreter:            defaultInterpreter(),
+		Command:                command,
+		Lifecycle:              script.LifecycleFunction,
+		Inputs:                 inputs,
+		TerminationGracePeriod: defaultTerminationGracePeriod,
+		ReadJSON:               true,
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	// Functions must return known values, so the unknown string literal is decoded as a string.
+	out, diags := tfdynamic.DecodeWithOptions(ctx, res.Output, tfdynamic.DecodeOptions{DisableUnknownStringLiteral: true})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, out)
+}
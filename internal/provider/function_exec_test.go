@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccExecFunction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exec", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"data": true}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"data": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+output "test" {
+  value = provider::shell::exec(<<-EOF
+    %s
+  EOF
+  , {})
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{"data": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("exec_with_inputs", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '%s' "$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `$env:TF_SCRIPT_INPUTS | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+output "test" {
+  value = provider::shell::exec(<<-EOF
+    %s
+  EOF
+  , { name = "test", count = 2, items = ["a", "b"] })
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"name":  knownvalue.StringExact("test"),
+							"count": knownvalue.Int64Exact(2),
+							"items": knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("a"), knownvalue.StringExact("b")}),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("exec_unknown_string_literal", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"data": "???"}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"data": "???"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+output "test" {
+  value = provider::shell::exec(<<-EOF
+    %s
+  EOF
+  , {})
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{"data": knownvalue.StringExact("???")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("error_no_json", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: `
+output "test" {
+  value = provider::shell::exec("exit 0", {})
+}
+`,
+					ExpectError: regexp.MustCompile(`Failed to read output file`),
+				},
+			},
+		})
+	})
+
+	t.Run("error_message", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf 'my-error' > "$${TF_SCRIPT_ERROR}"; exit 1`
+		if runtime.GOOS == "windows" {
+			cmd = `'my-error' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8; exit 1`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+output "test" {
+  value = provider::shell::exec(%q, {})
+}
+`, cmd),
+					ExpectError: regexp.MustCompile(`Command failed with exit code: 1(.|\n)*my-error`),
+				},
+			},
+		})
+	})
+}
@@ -3,15 +3,23 @@ package provider
 import (
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
 )
 
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
 	"shell": providerserver.NewProtocol6WithError(New("test", "test")()),
 }
 
+// testAccProtoV6ProviderFactoriesWithEcho includes the echo provider to make ephemeral values testable.
+var testAccProtoV6ProviderFactoriesWithEcho = map[string]func() (tfprotov6.ProviderServer, error){
+	"shell": providerserver.NewProtocol6WithError(New("test", "test")()),
+	"echo":  echoprovider.NewProviderServer(),
+}
+
 func testAccPreCheck(_ *testing.T) {
 }
 
@@ -48,3 +56,35 @@ func mustStringMap(t *testing.T, values map[string]string) types.Map {
 
 	return m
 }
+
+// mustStringListMap creates a types.Map of string lists for testing.
+func mustStringListMap(t *testing.T, values map[string][]string) types.Map {
+	t.Helper()
+
+	m, diags := types.MapValueFrom(t.Context(), types.ListType{ElemType: types.StringType}, values)
+	if diags.HasError() {
+		t.Fatalf("failed to create map: %v", diags.Errors())
+	}
+
+	return m
+}
+
+// mustRetryObject creates a retry types.Object for testing.
+func mustRetryObject(t *testing.T, model RetryModel) types.Object {
+	t.Helper()
+
+	obj, diags := types.ObjectValueFrom(t.Context(), map[string]attr.Type{
+		"max_attempts":            types.Int64Type,
+		"initial_backoff":         types.StringType,
+		"max_backoff":             types.StringType,
+		"multiplier":              types.Float64Type,
+		"jitter":                  types.Float64Type,
+		"retryable_exit_codes":    types.ListType{ElemType: types.Int64Type},
+		"retryable_error_pattern": types.StringType,
+	}, model)
+	if diags.HasError() {
+		t.Fatalf("failed to create object: %v", diags.Errors())
+	}
+
+	return obj
+}
@@ -2,22 +2,34 @@ package provider
 
 import (
 	"context"
-	"runtime"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
 )
 
 // Ensure ShellProvider satisfies various provider interfaces.
 var (
-	_ provider.Provider              = &ShellProvider{}
-	_ provider.ProviderWithFunctions = &ShellProvider{}
+	_ provider.Provider                       = &ShellProvider{}
+	_ provider.ProviderWithFunctions          = &ShellProvider{}
+	_ provider.ProviderWithEphemeralResources = &ShellProvider{}
+	_ provider.ProviderWithActions            = &ShellProvider{}
 )
 
 // New returns a new provider implementation.
@@ -32,12 +44,16 @@ func New(version, commit string) func() provider.Provider {
 
 // ShellProviderData is the data available to the resource and data sources.
 type ShellProviderData struct {
-	provider           *ShellProvider
-	Model              *ShellProviderModel
-	DefaultInterpreter []string
-	Environment        map[string]string
-	LogOutput          bool
-	DefaultTimeouts    *Timeouts
+	provider               *ShellProvider
+	Model                  *ShellProviderModel
+	DefaultInterpreter     []string
+	Environment            map[string]string
+	InheritEnvironment     shell.InheritEnvironment
+	LogOutput              bool
+	UnknownStringLiteral   bool
+	Retry                  script.RetryPolicy
+	TerminationGracePeriod time.Duration
+	DefaultTimeouts        *Timeouts
 }
 
 // Timeouts represents a set of timeouts.
@@ -50,9 +66,16 @@ type Timeouts struct {
 
 // ShellProviderModel describes the provider data model.
 type ShellProviderModel struct {
-	Environment types.Map      `tfsdk:"environment"`
-	LogOutput   types.Bool     `tfsdk:"log_output"`
-	Timeouts    timeouts.Value `tfsdk:"timeouts"`
+	Environment            types.Map      `tfsdk:"environment"`
+	InheritEnvironment     types.String   `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List     `tfsdk:"environment_passthrough"`
+	Interpreter            types.List     `tfsdk:"interpreter"`
+	OSInterpreters         types.Map      `tfsdk:"os_interpreters"`
+	LogOutput              types.Bool     `tfsdk:"log_output"`
+	UnknownStringLiteral   types.Bool     `tfsdk:"unknown_string_literal"`
+	Retry                  types.Object   `tfsdk:"retry"`
+	TerminationGracePeriod types.String   `tfsdk:"termination_grace_period"`
+	Timeouts               timeouts.Value `tfsdk:"timeouts"`
 }
 
 // ShellProvider defines the provider implementation.
@@ -77,11 +100,98 @@ func (p *ShellProvider) Schema(ctx context.Context, _ provider.SchemaRequest, re
 				ElementType:         types.StringType,
 				Optional:            true,
 			},
+			"inherit_environment": schema.StringAttribute{
+				Description:         "How the OS environment is inherited by scripts; this can be one of all, none or allowlist. Defaults to all.",
+				MarkdownDescription: "How the OS environment is inherited by scripts; this can be one of `all`, `none` or `allowlist`. Defaults to `all`. When set to `none` only the `environment` values and the `TF_SCRIPT_*` variables are set, and when set to `allowlist` only the OS environment variables matching `environment_passthrough` are also inherited.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inheritEnvironmentValues...),
+				},
+			},
+			"environment_passthrough": schema.ListAttribute{
+				Description:         "The OS environment variable names or glob patterns to inherit when inherit_environment is allowlist.",
+				MarkdownDescription: "The OS environment variable names or glob patterns (e.g. `AWS_*`) to inherit when `inherit_environment` is `allowlist`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"interpreter": schema.ListAttribute{
+				Description:         "The default interpreter to use for executing commands; if not set the provider default interpreter will be used.",
+				MarkdownDescription: "The default interpreter to use for executing commands; if not set the platform default interpreter (`/bin/bash -c` or `pwsh -c` on _Windows_) will be used. The interpreter executable must exist in `PATH`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"os_interpreters": schema.MapAttribute{
+				Description:         "A map of default interpreters to use for executing commands where the map key is the GOOS value; this takes precedence over interpreter.",
+				MarkdownDescription: "A map of default interpreters to use for executing commands where the map key is the `GOOS` value; this takes precedence over `interpreter`. The interpreter executable for the current platform must exist in `PATH`.",
+				ElementType:         types.ListType{ElemType: types.StringType},
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.ValueListsAre(listvalidator.SizeAtLeast(1)),
+				},
+			},
 			"log_output": schema.BoolAttribute{
 				Description:         "If true, lines output by the script will be logged at the appropriate level if they have a specific prefix.",
 				MarkdownDescription: "If `true`, lines output by the script will be logged at the appropriate level if they start with the `[<LEVEL>]` pattern where `<LEVEL>` can be one of `ERROR`, `WARN`, `INFO`, `DEBUG` & `TRACE`.",
 				Optional:            true,
 			},
+			"unknown_string_literal": schema.BoolAttribute{
+				Description:         "If false, ??? string values in the command output are decoded as strings instead of unknown values; defaults to true.",
+				MarkdownDescription: "If `false`, `???` string values in the command output are decoded as strings instead of unknown values; defaults to `true`. Plan commands can list the paths of unknown values in `__meta.unknown_paths` instead.",
+				Optional:            true,
+			},
+			"retry": schema.SingleNestedAttribute{
+				Description:         "The default retry policy for commands that exit with a non-zero code.",
+				MarkdownDescription: "The default retry policy for resource and data source commands that exit with a non-zero code. Each attempt is logged and retries stop once the `timeouts` deadline would be reached.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: "The maximum number of times to run a command, including the first attempt. Defaults to `1`.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"initial_backoff": schema.StringAttribute{
+						MarkdownDescription: "The time to wait before the first retry. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. Defaults to `1s`.",
+						Optional:            true,
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "The maximum time to wait between retries. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. Defaults to `30s`.",
+						Optional:            true,
+					},
+					"multiplier": schema.Float64Attribute{
+						MarkdownDescription: "The multiplier applied to the backoff after each retry. Defaults to `2`.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.AtLeast(1),
+						},
+					},
+					"jitter": schema.Float64Attribute{
+						MarkdownDescription: "The maximum fraction of the backoff, between `0` and `1`, to randomly add or subtract. Defaults to `0`.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.Between(0, 1),
+						},
+					},
+					"retryable_exit_codes": schema.ListAttribute{
+						MarkdownDescription: "The exit codes to retry; if neither this nor `retryable_error_pattern` are set every non-zero exit code is retried, otherwise a failure is retried if either matches.",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+					},
+					"retryable_error_pattern": schema.StringAttribute{
+						MarkdownDescription: "A regular expression matched against the contents of the file defined by the `TF_SCRIPT_ERROR` environment variable to decide if a failure is retried.",
+						Optional:            true,
+					},
+				},
+			},
+			"termination_grace_period": schema.StringAttribute{
+				Description:         "The time to wait for a timed out command to exit after it has been sent SIGTERM before it is killed; defaults to 10s.",
+				MarkdownDescription: "The time to wait for a timed out or cancelled command to exit after `SIGTERM` has been sent to its process group before the group is killed with `SIGKILL`; defaults to `10s`. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `10s` or `1m`. On _Windows_ the command is killed without a grace period.",
+				Optional:            true,
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create:            true,
 				CreateDescription: "Timeout for resource creation; defaults to `10m`. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) consisting of numbers and unit suffixes, such as `30s` or `2h45m`. Valid time units are `s` (seconds), `m` (minutes), `h` (hours).",
@@ -110,14 +220,6 @@ func (p *ShellProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
-	// Get the default interpreter
-	var interpreter []string
-	if runtime.GOOS == "windows" {
-		interpreter = []string{"pwsh", "-c"}
-	} else {
-		interpreter = []string{"/bin/bash", "-c"}
-	}
-
 	// Set the environment
 	environment := map[string]string{}
 	if !model.Environment.IsNull() {
@@ -126,31 +228,59 @@ func (p *ShellProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		}
 	}
 
+	// Resolve the OS environment inheritance
+	inherit, diags := resolveInheritEnvironment(ctx, model.InheritEnvironment, model.EnvironmentPassthrough, shell.InheritEnvironment{Mode: shell.EnvironmentModeAll})
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Resolve the default interpreter
+	interpreter, diags := resolveDefaultInterpreter(ctx, model.Interpreter, model.OSInterpreters)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Resolve the retry policy
+	retry, diags := resolveRetryPolicy(ctx, model.Retry, defaultRetryPolicy)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Resolve the termination grace period
+	terminationGracePeriod, diags := resolveTerminationGracePeriod(model.TerminationGracePeriod, defaultTerminationGracePeriod)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Lookup timeouts
-	createTimeout, diags := model.Timeouts.Create(ctx, 10*time.Minute)
+	createTimeout, diags := model.Timeouts.Create(ctx, defaultTimeout)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
-	readTimeout, diags := model.Timeouts.Read(ctx, 10*time.Minute)
+	readTimeout, diags := model.Timeouts.Read(ctx, defaultTimeout)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
-	updateTimeout, diags := model.Timeouts.Update(ctx, 10*time.Minute)
+	updateTimeout, diags := model.Timeouts.Update(ctx, defaultTimeout)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
-	deleteTimeout, diags := model.Timeouts.Delete(ctx, 10*time.Minute)
+	deleteTimeout, diags := model.Timeouts.Delete(ctx, defaultTimeout)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Configure provider data
 	providerData := &ShellProviderData{
-		provider:           p,
-		Model:              model,
-		DefaultInterpreter: interpreter,
-		Environment:        environment,
-		LogOutput:          model.LogOutput.ValueBool(),
+		provider:               p,
+		Model:                  model,
+		DefaultInterpreter:     interpreter,
+		Environment:            environment,
+		InheritEnvironment:     inherit,
+		LogOutput:              model.LogOutput.ValueBool(),
+		UnknownStringLiteral:   model.UnknownStringLiteral.IsNull() || model.UnknownStringLiteral.ValueBool(),
+		Retry:                  retry,
+		TerminationGracePeriod: terminationGracePeriod,
 		DefaultTimeouts: &Timeouts{
 			Create: createTimeout,
 			Read:   readTimeout,
@@ -161,6 +291,14 @@ func (p *ShellProvider) Configure(ctx context.Context, req provider.ConfigureReq
 
 	resp.DataSourceData = providerData
 	resp.ResourceData = providerData
+	resp.EphemeralResourceData = providerData
+	resp.ActionData = providerData
+}
+
+func (p *ShellProvider) Actions(_ context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewCommandAction,
+	}
 }
 
 func (p *ShellProvider) DataSources(_ context.Context) []func() datasource.DataSource {
@@ -169,8 +307,16 @@ func (p *ShellProvider) DataSources(_ context.Context) []func() datasource.DataS
 	}
 }
 
+func (p *ShellProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewScriptEphemeralResource,
+	}
+}
+
 func (p *ShellProvider) Functions(_ context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewExecFunction,
+	}
 }
 
 func (p *ShellProvider) Resources(_ context.Context) []func() resource.Resource {
@@ -1 +1,260 @@
 package provider
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccShellProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("interpreter", func(t *testing.T) {
+		t.Parallel()
+
+		interpreter := `["/bin/sh", "-c"]`
+		cmd := `printf '{"interpreter": "%s"}' "$0" > "$${TF_SCRIPT_OUTPUT}"`
+		want := "/bin/sh"
+		if runtime.GOOS == "windows" {
+			interpreter = `["powershell", "-Command"]`
+			cmd = `'{"interpreter": "powershell"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+			want = "powershell"
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+provider "shell" {
+  interpreter = %s
+}
+
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+    }
+  }
+}
+`, interpreter, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"interpreter": knownvalue.StringExact(want)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("os_interpreters", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+provider "shell" {
+  interpreter = ["missing-interpreter", "-c"]
+  os_interpreters = {
+    darwin  = ["/bin/sh", "-c"]
+    linux   = ["/bin/sh", "-c"]
+    windows = ["powershell", "-Command"]
+  }
+}
+
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"interpreter": "%s"}' "$0" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          '{"interpreter": "powershell"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectPartial(map[string]knownvalue.Check{"interpreter": knownvalue.NotNull()})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("inherit_environment", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+provider "shell" {
+  inherit_environment     = "allowlist"
+  environment_passthrough = ["NOT_TF_ACC"]
+}
+
+data "shell_script" "test" {
+  environment_passthrough = ["TF_ACC"]
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"tf_acc": "%s"}' "$${TF_ACC:-}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          @{tf_acc="$env:TF_ACC"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+
+data "shell_script" "test_provider" {
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          printf '{"tf_acc": "%s"}' "$${TF_ACC:-}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          @{tf_acc="$env:TF_ACC"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"tf_acc": knownvalue.StringExact("1")})),
+						statecheck.ExpectKnownValue("data.shell_script.test_provider", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"tf_acc": knownvalue.StringExact("")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+provider "shell" {
+  retry = {
+    max_attempts    = 2
+    initial_backoff = "10ms"
+  }
+}
+
+data "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  os_commands = {
+    default = {
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          if [[ ! -f "$${path}" ]]; then
+            touch "$${path}"
+            exit 1
+          fi
+          printf '{"success": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+    }
+    windows = {
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          if (-not (Test-Path $inputs.path)) {
+            New-Item -ItemType File -Path $inputs.path | Out-Null
+            exit 1
+          }
+          '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+    }
+  }
+}
+`, file),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("data.shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"success": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("error_interpreter_not_found", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+provider "shell" {
+  interpreter = ["missing-interpreter", "-c"]
+}
+
+data "shell_script" "test" {
+  os_commands = {
+    default = {
+      read = {
+        command = "exit 0"
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Interpreter not found`),
+				},
+			},
+		})
+	})
+}
@@ -3,10 +3,16 @@ package provider
 import (
 	"context"
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -14,6 +20,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 
 	"github.com/terr4m/terraform-provider-shell/internal/script"
 	"github.com/terr4m/terraform-provider-shell/internal/shell"
@@ -25,10 +32,25 @@ var (
 	_ resource.ResourceWithConfigure      = &ScriptResource{}
 	_ resource.ResourceWithValidateConfig = &ScriptResource{}
 	_ resource.ResourceWithModifyPlan     = &ScriptResource{}
+	_ resource.ResourceWithImportState    = &ScriptResource{}
 )
 
-// defaultCommandsKey is the key in the os_commands map for the default commands.
-const defaultCommandsKey = "default"
+const (
+	// defaultCommandsKey is the key in the os_commands map for the default commands.
+	defaultCommandsKey = "default"
+
+	// importIDPrivateKey is the private state key holding the ID of a pending import.
+	importIDPrivateKey = "import_id"
+
+	// importResultPrivateKey is the private state key holding the result of the import command.
+	importResultPrivateKey = "import_result"
+
+	// operationContextPrivateKey is the private state key holding the operation context of a planned replacement.
+	operationContextPrivateKey = "operation_context"
+
+	// scriptPrivateKey is the private state key holding the __private value returned by the commands.
+	scriptPrivateKey = "script_private"
+)
 
 // NewScriptResource creates a new resource resource.
 func NewScriptResource() resource.Resource {
@@ -43,14 +65,32 @@ type ScriptResource struct {
 
 // ScriptResourceModel describes the resource data model.
 type ScriptResourceModel struct {
-	Environment      types.Map      `tfsdk:"environment"`
-	WorkingDirectory types.String   `tfsdk:"working_directory"`
-	Inputs           types.Dynamic  `tfsdk:"inputs"`
-	OSCommands       types.Map      `tfsdk:"os_commands"`
-	Output           types.Dynamic  `tfsdk:"output"`
-	OutputDrift      types.Bool     `tfsdk:"output_drift"`
-	Triggers         types.Dynamic  `tfsdk:"triggers"`
-	Timeouts         timeouts.Value `tfsdk:"timeouts"`
+	Environment              types.Map      `tfsdk:"environment"`
+	SensitiveEnvironment     types.Map      `tfsdk:"sensitive_environment"`
+	InheritEnvironment       types.String   `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough   types.List     `tfsdk:"environment_passthrough"`
+	WorkingDirectory         types.String   `tfsdk:"working_directory"`
+	InputMode                types.String   `tfsdk:"input_mode"`
+	OutputSource             types.String   `tfsdk:"output_source"`
+	OutputFormat             types.String   `tfsdk:"output_format"`
+	OutputCollectionMode     types.String   `tfsdk:"output_collection_mode"`
+	OutputType               types.String   `tfsdk:"output_type"`
+	OutputSchema             types.String   `tfsdk:"output_schema"`
+	InputsSchema             types.String   `tfsdk:"inputs_schema"`
+	Inputs                   types.Dynamic  `tfsdk:"inputs"`
+	SensitiveInputs          types.Dynamic  `tfsdk:"sensitive_inputs"`
+	InputsWO                 types.Dynamic  `tfsdk:"inputs_wo"`
+	InputsWOVersion          types.Int64    `tfsdk:"inputs_wo_version"`
+	ReplaceTriggeredByInputs types.List     `tfsdk:"replace_triggered_by_inputs"`
+	OSCommands               types.Map      `tfsdk:"os_commands"`
+	SensitiveOutput          types.Bool     `tfsdk:"sensitive_output"`
+	Output                   types.Dynamic  `tfsdk:"output"`
+	OutputSensitive          types.Dynamic  `tfsdk:"output_sensitive"`
+	OutputDrift              types.Bool     `tfsdk:"output_drift"`
+	Triggers                 types.Dynamic  `tfsdk:"triggers"`
+	Retry                    types.Object   `tfsdk:"retry"`
+	TerminationGracePeriod   types.String   `tfsdk:"termination_grace_period"`
+	Timeouts                 timeouts.Value `tfsdk:"timeouts"`
 }
 
 // CRUDCommandsModel describes a set of CRUD commands.
@@ -60,6 +100,7 @@ type CRUDCommandsModel struct {
 	Read   CommandModel  `tfsdk:"read"`
 	Update CommandModel  `tfsdk:"update"`
 	Delete CommandModel  `tfsdk:"delete"`
+	Import *CommandModel `tfsdk:"import"`
 }
 
 // CommandModel describes an interpreter and a command string.
@@ -68,6 +109,12 @@ type CommandModel struct {
 	Command     types.String `tfsdk:"command"`
 }
 
+// importResult describes the output of an import command.
+type importResult struct {
+	Inputs any `json:"inputs"`
+	Output any `json:"output"`
+}
+
 // Metadata returns the resource metadata.
 func (r *ScriptResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = fmt.Sprintf("%s_script", req.ProviderTypeName)
@@ -85,16 +132,113 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 				ElementType:         types.StringType,
 				Optional:            true,
 			},
+			"sensitive_environment": schema.MapAttribute{
+				Description:         "Sensitive environment variables to set when executing commands; to be combined with the environment. Values of at least 4 characters are redacted from logged output.",
+				MarkdownDescription: "Sensitive environment variables to set when executing commands; to be combined with the `environment`. Values of at least 4 characters are redacted from logged output.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"inherit_environment": schema.StringAttribute{
+				Description:         "How the OS environment is inherited by the commands; this can be one of all, none or allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "How the OS environment is inherited by the commands; this can be one of `all`, `none` or `allowlist`. This defaults to the provider value if not set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inheritEnvironmentValues...),
+				},
+			},
+			"environment_passthrough": schema.ListAttribute{
+				Description:         "The OS environment variable names or glob patterns to inherit when inherit_environment is allowlist. This defaults to the provider value if not set.",
+				MarkdownDescription: "The OS environment variable names or glob patterns (e.g. `AWS_*`) to inherit when `inherit_environment` is `allowlist`. This defaults to the provider value if not set.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
 			"working_directory": schema.StringAttribute{
 				Description:         "The working directory to use when executing the commands; this will default to the Terraform working directory.",
 				MarkdownDescription: "The working directory to use when executing the commands; this will default to the _Terraform_ working directory.",
 				Optional:            true,
 			},
+			"input_mode": schema.StringAttribute{
+				Description:         "How the JSON inputs are passed to the commands; this can be one of env, file or stdin. This defaults to env.",
+				MarkdownDescription: "How the JSON inputs are passed to the commands; this can be one of `env`, `file` or `stdin`. This defaults to `env`, which sets the `TF_SCRIPT_*` environment variables. When set to `file` the JSON is written to temporary files with their paths in the matching `TF_SCRIPT_*_FILE` environment variables, and when set to `stdin` a single JSON document with the `lifecycle`, `inputs`, `sensitive_inputs`, `inputs_wo`, `state_output` and `private` keys is written to the standard input.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(inputModeValues...),
+				},
+			},
+			"output_source": schema.StringAttribute{
+				Description:         "Where the JSON output is read from; this can be one of file or stdout. This defaults to file.",
+				MarkdownDescription: "Where the JSON output is read from; this can be one of `file` or `stdout`. This defaults to `file`, which reads the file at the path in the `TF_SCRIPT_OUTPUT` environment variable. When set to `stdout` the output is read from the standard output of the commands instead, and only the standard error is logged when the provider `log_output` is enabled.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputSourceValues...),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				Description:         "The format of the output; this can be one of json, yaml, toml, dotenv or raw. This defaults to json.",
+				MarkdownDescription: "The format of the output; this can be one of `json`, `yaml`, `toml`, `dotenv` or `raw`. This defaults to `json`. The `dotenv` format reads `KEY=VALUE` lines into an object of strings and the `raw` format sets `output` to the output as a plain string with a single trailing newline removed.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormatValues...),
+				},
+			},
+			"output_collection_mode": schema.StringAttribute{
+				Description:         "How arrays and objects in the output are decoded; this can be one of structural or infer. This defaults to structural.",
+				MarkdownDescription: "How arrays and objects in the output are decoded; this can be one of `structural` or `infer`. This defaults to `structural`, which decodes arrays to tuples and objects to objects. When set to `infer` arrays and objects whose elements all have the same type are decoded to lists and maps, which can be used directly with `for_each` and collection functions; any array or object containing an unknown value is unknown during plan as its type can't be inferred.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputCollectionModeValues...),
+				},
+			},
+			"output_type": schema.StringAttribute{
+				Description:         "Terraform type constraint which the output is converted to, e.g. object({id=string, tags=optional(map(string))}).",
+				MarkdownDescription: "Terraform type constraint which the output is converted to, e.g. `object({id=string, tags=optional(map(string))})`; output which doesn't match the type is an error. Missing `optional` attributes are set to their default or `null`. When set this takes precedence over `output_collection_mode` and can't be combined with `__meta.sensitive_paths`.",
+				Optional:            true,
+			},
+			"output_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the command output is validated against; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the command output is validated against, without the `__meta` key; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error. The output of the `plan` command isn't validated as it can contain unknown values.",
+				Optional:            true,
+			},
+			"inputs_schema": schema.StringAttribute{
+				Description:         "JSON Schema which the inputs are validated against before any command runs; this can be an inline JSON object or the path to a file.",
+				MarkdownDescription: "[JSON Schema](https://json-schema.org/) which the `inputs` are validated against before any command runs; this can be an inline JSON object, e.g. from `jsonencode()`, or the path to a file. Each value which doesn't match the schema is reported as a separate error.",
+				Optional:            true,
+			},
 			"inputs": schema.DynamicAttribute{
 				Description:         "Inputs to be made available to the script.",
 				MarkdownDescription: "Inputs to be made available to the script; these can be accessed as JSON via the `TF_SCRIPT_INPUTS` environment variable.",
 				Optional:            true,
 			},
+			"sensitive_inputs": schema.DynamicAttribute{
+				Description:         "Sensitive inputs to be made available to the script.",
+				MarkdownDescription: "Sensitive inputs to be made available to the script; these can be accessed as JSON via the `TF_SCRIPT_SENSITIVE_INPUTS` environment variable. String and number values of at least 4 characters are redacted from logged output; bool values aren't redacted.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"inputs_wo": schema.DynamicAttribute{
+				Description:         "Write-only inputs to be made available to the create and update commands; these are never persisted to the plan or state.",
+				MarkdownDescription: "Write-only inputs to be made available to the create and update commands; these can be accessed as JSON via the `TF_SCRIPT_INPUTS_WO` environment variable and are never persisted to the plan or state. String and number values of at least 4 characters are redacted from logged output; bool values aren't redacted. Changes are only applied when `inputs_wo_version` changes.",
+				Optional:            true,
+				WriteOnly:           true,
+			},
+			"inputs_wo_version": schema.Int64Attribute{
+				Description:         "The version of the write-only inputs; changing this value triggers an update with the current inputs_wo.",
+				MarkdownDescription: "The version of the write-only inputs; changing this value triggers an update with the current `inputs_wo`.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("inputs_wo")),
+				},
+			},
+			"replace_triggered_by_inputs": schema.ListAttribute{
+				Description:         "The input paths which replace the resource instead of updating it when their values change, e.g. region or cluster.name.",
+				MarkdownDescription: "The input paths which replace the resource instead of updating it when their values change, e.g. `[\"region\", \"cluster.name\"]`; paths are dot separated object keys with optional list indexes (e.g. `disks[0].type`).",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.NoNullValues(),
+				},
+			},
 			"os_commands": schema.MapNestedAttribute{
 				Description:         "A map of commands to run as part of the Terraform lifecycle where the map key is the GOOS value or default; default must be provided.",
 				MarkdownDescription: "A map of commands to run as part of the Terraform lifecycle where the map key is the `GOOS` value or `default`; `default` must be provided.",
@@ -106,7 +250,7 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 							Optional:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the plan command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the plan command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -124,7 +268,7 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 							Required:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the create command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the create command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -142,7 +286,7 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 							Required:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the read command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the read command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -160,7 +304,7 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 							Required:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the update command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the update command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -178,7 +322,7 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 							Required:            true,
 							Attributes: map[string]schema.Attribute{
 								"interpreter": schema.ListAttribute{
-									MarkdownDescription: "The interpreter to use for executing the delete command; if not set the platform default interpreter will be used.",
+									MarkdownDescription: "The interpreter to use for executing the delete command; if not set the provider default interpreter will be used.",
 									ElementType:         types.StringType,
 									Optional:            true,
 									Validators: []validator.List{
@@ -191,14 +335,43 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 								},
 							},
 						},
+						"import": schema.SingleNestedAttribute{
+							MarkdownDescription: "The import command configuration, this is required to import existing resources; the command receives the import ID via the `TF_SCRIPT_IMPORT_ID` environment variable and must output a JSON object with the `inputs` and `output` keys.",
+							Optional:            true,
+							Attributes: map[string]schema.Attribute{
+								"interpreter": schema.ListAttribute{
+									MarkdownDescription: "The interpreter to use for executing the import command; if not set the provider default interpreter will be used.",
+									ElementType:         types.StringType,
+									Optional:            true,
+									Validators: []validator.List{
+										listvalidator.SizeAtLeast(1),
+									},
+								},
+								"command": schema.StringAttribute{
+									MarkdownDescription: "The import command to execute.",
+									Required:            true,
+								},
+							},
+						},
 					},
 				},
 			},
+			"sensitive_output": schema.BoolAttribute{
+				Description:         "If true the whole script output is returned in the sensitive output_sensitive attribute instead of the output attribute.",
+				MarkdownDescription: "If `true` the whole script output is returned in the sensitive `output_sensitive` attribute instead of the `output` attribute.",
+				Optional:            true,
+			},
 			"output": schema.DynamicAttribute{
 				Description:         "The output of the script as a structured type.",
 				MarkdownDescription: "The output of the script as a structured type; this can be accessed in the read, update and delete commands as JSON via the `TF_SCRIPT_STATE_OUTPUT` environment variable.",
 				Computed:            true,
 			},
+			"output_sensitive": schema.DynamicAttribute{
+				Description:         "The sensitive part of the output of the script as a structured type.",
+				MarkdownDescription: "The sensitive part of the output of the script as a structured type; this contains the whole output if `sensitive_output` is `true`, otherwise the values at any paths listed in `__meta.sensitive_paths`. This is merged back into the output made available via the `TF_SCRIPT_STATE_OUTPUT` environment variable.",
+				Computed:            true,
+				Sensitive:           true,
+			},
 			"output_drift": schema.BoolAttribute{
 				Description:         "If the output has drifted and needs reconciling.",
 				MarkdownDescription: "If the output has drifted and needs reconciling.",
@@ -209,9 +382,59 @@ func (r *ScriptResource) Schema(ctx context.Context, _ resource.SchemaRequest, r
 				MarkdownDescription: "Allows specifying values that trigger resource replacement when changed.",
 				Optional:            true,
 				PlanModifiers: []planmodifier.Dynamic{
-					dynamicplanmodifier.RequiresReplace(),
+					dynamicplanmodifier.RequiresReplaceIf(triggersRequireReplace, "Changes to the triggers require replacement unless the resource is being imported.", "Changes to the triggers require replacement unless the resource is being imported."),
 				},
 			},
+			"retry": schema.SingleNestedAttribute{
+				Description:         "The retry policy for commands that exit with a non-zero code; this defaults to the provider value for each attribute that isn't set.",
+				MarkdownDescription: "The retry policy for commands that exit with a non-zero code; this defaults to the provider value for each attribute that isn't set. Each attempt is logged and retries stop once the `timeouts` deadline would be reached.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: "The maximum number of times to run a command, including the first attempt. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"initial_backoff": schema.StringAttribute{
+						MarkdownDescription: "The time to wait before the first retry. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "The maximum time to wait between retries. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `1s` or `1m30s`. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+					"multiplier": schema.Float64Attribute{
+						MarkdownDescription: "The multiplier applied to the backoff after each retry. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.AtLeast(1),
+						},
+					},
+					"jitter": schema.Float64Attribute{
+						MarkdownDescription: "The maximum fraction of the backoff, between `0` and `1`, to randomly add or subtract. This defaults to the provider value if not set.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							float64validator.Between(0, 1),
+						},
+					},
+					"retryable_exit_codes": schema.ListAttribute{
+						MarkdownDescription: "The exit codes to retry; if neither this nor `retryable_error_pattern` are set every non-zero exit code is retried, otherwise a failure is retried if either matches. This defaults to the provider value if not set.",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+					},
+					"retryable_error_pattern": schema.StringAttribute{
+						MarkdownDescription: "A regular expression matched against the contents of the file defined by the `TF_SCRIPT_ERROR` environment variable to decide if a failure is retried. This defaults to the provider value if not set.",
+						Optional:            true,
+					},
+				},
+			},
+			"termination_grace_period": schema.StringAttribute{
+				Description:         "The time to wait for a timed out command to exit after it has been sent SIGTERM before it is killed; this defaults to the provider value if not set.",
+				MarkdownDescription: "The time to wait for a timed out or cancelled command to exit after `SIGTERM` has been sent to its process group before the group is killed with `SIGKILL`; this defaults to the provider value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) such as `10s` or `1m`.",
+				Optional:            true,
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create:            true,
 				CreateDescription: "Timeout for creating the resource; this defaults to the provider value if not set. This should be a string that can be [parsed as a duration](https://pkg.go.dev/time#ParseDuration) consisting of numbers and unit suffixes, such as `30s` or `2h45m`. Valid time units are `s` (seconds), `m` (minutes), `h` (hours).",
@@ -267,6 +490,19 @@ func (r *ScriptResource) ValidateConfig(ctx context.Context, req resource.Valida
 		resp.Diagnostics.AddAttributeError(path.Root("os_commands").AtMapKey(defaultCommandsKey), "Default commands are required.", "expected default to be set in os_commands")
 		return
 	}
+
+	_, diags := resolveRetryPolicy(ctx, conf.Retry, script.RetryPolicy{})
+	resp.Diagnostics.Append(diags...)
+
+	_, diags = resolveTerminationGracePeriod(conf.TerminationGracePeriod, 0)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(validateOutputType(conf.OutputType)...)
+
+	resp.Diagnostics.Append(validateSchema(conf.OutputSchema, "output_schema")...)
+	resp.Diagnostics.Append(validateInputs(ctx, conf.InputsSchema, conf.Inputs)...)
+
+	resp.Diagnostics.Append(validateInputPaths(ctx, conf.ReplaceTriggeredByInputs)...)
 }
 
 // ModifyPlan modifies the resource plan.
@@ -280,6 +516,10 @@ func (r *ScriptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlan
 		return
 	}
 
+	if resp.Diagnostics.Append(validateInputs(ctx, plan.InputsSchema, plan.Inputs)...); resp.Diagnostics.HasError() {
+		return
+	}
+
 	var osCommands map[string]CRUDCommandsModel
 	if resp.Diagnostics.Append(plan.OSCommands.ElementsAs(ctx, &osCommands, false)...); resp.Diagnostics.HasError() {
 		return
@@ -291,76 +531,365 @@ func (r *ScriptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlan
 		commands = osCommands[defaultCommandsKey]
 	}
 
+	importID, diags := getPrivateValue[string](ctx, req.Private, importIDPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if importID != nil {
+		r.modifyImportPlan(ctx, *importID, commands, &plan, resp)
+		return
+	}
+
+	var state *ScriptResourceModel
+	if !req.State.Raw.IsNull() {
+		if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if state != nil && !plan.ReplaceTriggeredByInputs.IsNull() && !plan.ReplaceTriggeredByInputs.IsUnknown() {
+		var inputPaths []string
+		if resp.Diagnostics.Append(plan.ReplaceTriggeredByInputs.ElementsAs(ctx, &inputPaths, false)...); resp.Diagnostics.HasError() {
+			return
+		}
+
+		paths, diags := changedInputPaths(ctx, state.Inputs, plan.Inputs, inputPaths)
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.RequiresReplace = append(resp.RequiresReplace, paths...)
+	}
+
 	if commands.Plan == nil {
 		if !plan.OutputDrift.ValueBool() {
+			resp.Diagnostics.Append(setOperationContext(ctx, state, resp)...)
 			return
 		}
 
 		plan.Output = types.DynamicUnknown()
+		plan.OutputSensitive = types.DynamicUnknown()
 	} else {
 		timeout, diags := plan.Timeouts.Read(ctx, r.providerData.DefaultTimeouts.Read)
 		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 			return
 		}
 
-		inputs, err := tfdynamic.EncodeDynamic(ctx, plan.Inputs)
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+		opts, diags := r.runOptions(ctx, &plan, *commands.Plan)
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 			return
 		}
+		opts.Lifecycle = script.LifecyclePlan
 
-		var stateOutput any
-		if !req.State.Raw.IsNull() {
-			var state ScriptResourceModel
-			if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
-				return
-			}
-
-			stateOutput, err = tfdynamic.EncodeDynamic(ctx, state.Output)
+		if state != nil {
+			stateOutput, err := encodeStateOutput(ctx, state.Output, state.OutputSensitive)
 			if err != nil {
 				resp.Diagnostics.AddError("Failed to encode the state output.", err.Error())
 				return
 			}
+			opts.StateOutput = stateOutput
 		}
 
 		ctx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		interpreter, diags := resolveInterpreter(ctx, commands.Plan.Interpreter, r.providerData.DefaultInterpreter)
+		res, diags := r.runner.Run(ctx, opts)
 		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 			return
 		}
 
-		environment, diags := resolveEnvironment(ctx, plan.Environment, r.providerData.Environment)
-		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		output, err := tfdynamic.SetUnknownPaths(res.Output, res.Meta.UnknownPaths)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid unknown paths.", err.Error())
 			return
 		}
 
-		res, diags := r.runner.Run(ctx, script.RunOptions{
-			Interpreter:      interpreter,
-			Environment:      environment,
-			WorkingDirectory: plan.WorkingDirectory.ValueString(),
-			Command:          commands.Plan.Command.ValueString(),
-			Lifecycle:        script.LifecyclePlan,
-			Inputs:           inputs,
-			StateOutput:      stateOutput,
-			ReadJSON:         true,
-		})
+		out, sensitiveOut, diags := decodeOutput(ctx, output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, plan.OutputType))
 		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 			return
 		}
+		plan.Output = out
+		plan.OutputSensitive = sensitiveOut
+
+		if state != nil {
+			switch {
+			case res.Meta.RequiresReplace:
+				paths, diags := changedAttributePaths(req.State.Raw, req.Plan.Raw)
+				if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+					return
+				}
+
+				// Terraform ignores paths whose values haven't changed, so if no attributes have changed the output is
+				// planned as unknown to guarantee the replacement.
+				if len(paths) == 0 {
+					plan.Output = types.DynamicUnknown()
+					plan.OutputSensitive = types.DynamicUnknown()
+					paths = path.Paths{path.Root("output")}
+				}
+
+				resp.RequiresReplace = append(resp.RequiresReplace, paths...)
+			case len(res.Meta.RequiresReplacePaths) > 0:
+				paths, diags := changedInputPaths(ctx, state.Inputs, plan.Inputs, res.Meta.RequiresReplacePaths)
+				if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.RequiresReplace = append(resp.RequiresReplace, paths...)
+			}
+		}
+	}
 
-		out, diags := tfdynamic.Decode(ctx, res.Output)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
+	if resp.Diagnostics.Append(setOperationContext(ctx, state, resp)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.OutputDrift = types.BoolValue(false)
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// runOptions resolves the options to run the command from the model; the caller sets the lifecycle and the payloads
+// specific to it.
+func (r *ScriptResource) runOptions(ctx context.Context, model *ScriptResourceModel, command CommandModel) (script.RunOptions, diag.Diagnostics) {
+	opts, diags := resolveRunOptions(ctx, r.providerData, runConfig{
+		Environment:            model.Environment,
+		SensitiveEnvironment:   model.SensitiveEnvironment,
+		InheritEnvironment:     model.InheritEnvironment,
+		EnvironmentPassthrough: model.EnvironmentPassthrough,
+		WorkingDirectory:       model.WorkingDirectory,
+		InputMode:              model.InputMode,
+		OutputSource:           model.OutputSource,
+		OutputFormat:           model.OutputFormat,
+		OutputSchema:           model.OutputSchema,
+		Inputs:                 model.Inputs,
+		SensitiveInputs:        model.SensitiveInputs,
+		TerminationGracePeriod: model.TerminationGracePeriod,
+	}, command)
+	if diags.HasError() {
+		return opts, diags
+	}
+
+	var d diag.Diagnostics
+	opts.Retry, d = resolveRetryPolicy(ctx, model.Retry, r.providerData.Retry)
+	diags.Append(d...)
+
+	return opts, diags
+}
+
+// setOperationContext records in the private state planned for an existing resource that it's being replaced so the
+// delete command can be told why it's being run. Terraform can replace the resource for reasons the plan can't see,
+// such as -replace or replace_triggered_by, so this is recorded for every change and cleared by the update; the private
+// state planned for the replaced resource is kept when the replacement is planned as a create, as that's the private
+// state passed to the delete.
+func setOperationContext(ctx context.Context, state *ScriptResourceModel, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	if state == nil {
+		return nil
+	}
+
+	return setPrivateValue(ctx, resp.Private, operationContextPrivateKey, script.OperationContextReplace)
+}
+
+// changedAttributePaths returns the paths of the top level attributes, other than the computed output attributes, whose
+// values differ between the state and the plan.
+func changedAttributePaths(state, plan tftypes.Value) (path.Paths, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	var stateAttrs, planAttrs map[string]tftypes.Value
+	if err := state.As(&stateAttrs); err != nil {
+		diags.AddError("Failed to read the state.", err.Error())
+		return nil, diags
+	}
+
+	if err := plan.As(&planAttrs); err != nil {
+		diags.AddError("Failed to read the plan.", err.Error())
+		return nil, diags
+	}
+
+	var paths path.Paths
+	for _, name := range slices.Sorted(maps.Keys(planAttrs)) {
+		switch name {
+		case "output", "output_sensitive", "output_drift":
+			continue
+		}
+
+		if !planAttrs[name].Equal(stateAttrs[name]) {
+			paths = append(paths, path.Root(name))
+		}
+	}
+
+	return paths, diags
+}
+
+// changedInputPaths returns the attribute paths of the input paths whose values differ between the state and the plan.
+func changedInputPaths(ctx context.Context, stateInputs, planInputs types.Dynamic, inputPaths []string) (path.Paths, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	var paths path.Paths
+	for _, p := range inputPaths {
+		ap, err := tfdynamic.AttributePath(path.Root("inputs"), p)
+		if err != nil {
+			diags.AddError("Invalid input paths.", err.Error())
+			return nil, diags
+		}
+
+		changed, err := tfdynamic.PathChanged(ctx, stateInputs, planInputs, p)
+		if err != nil {
+			diags.AddError("Invalid input paths.", err.Error())
+			return nil, diags
+		}
+
+		if changed {
+			paths = append(paths, ap)
+		}
+	}
+
+	return paths, diags
+}
+
+// validateInputPaths validates that each known input path in the list can be parsed.
+func validateInputPaths(ctx context.Context, tfInputPaths types.List) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	if tfInputPaths.IsNull() || tfInputPaths.IsUnknown() {
+		return diags
+	}
+
+	var inputPaths []types.String
+	if diags.Append(tfInputPaths.ElementsAs(ctx, &inputPaths, false)...); diags.HasError() {
+		return diags
+	}
+
+	for i, p := range inputPaths {
+		if p.IsNull() || p.IsUnknown() {
+			continue
+		}
+
+		if _, err := tfdynamic.AttributePath(path.Root("inputs"), p.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("replace_triggered_by_inputs").AtListIndex(i), "Invalid input path.", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// setPartialState saves the output written by a failed create command to the state, which Terraform marks as tainted so
+// the next apply runs the delete command with the output; the output isn't converted to the output type as it's
+// incomplete.
+func (r *ScriptResource) setPartialState(ctx context.Context, res script.RunResult, plan *ScriptResourceModel, resp *resource.CreateResponse) {
+	// The response already has the command errors, so only the errors from saving the state are checked.
+	out, sensitiveOut, diags := decodeOutput(ctx, res.Output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, types.StringNull()))
+	if resp.Diagnostics.Append(diags...); diags.HasError() {
+		return
+	}
+	plan.Output = out
+	plan.OutputSensitive = sensitiveOut
+	plan.OutputDrift = types.BoolValue(false)
+
+	diags = setScriptPrivate(ctx, resp.Private, res.Private)
+	if resp.Diagnostics.Append(diags...); diags.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// triggersRequireReplace requires the resource to be replaced when the triggers change, unless an import is pending as
+// the imported state doesn't have the configured triggers until the import has been applied.
+func triggersRequireReplace(ctx context.Context, req planmodifier.DynamicRequest, resp *dynamicplanmodifier.RequiresReplaceIfFuncResponse) {
+	importID, diags := getPrivateValue[string](ctx, req.Private, importIDPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.RequiresReplace = importID == nil
+}
+
+// getScriptPrivate returns the __private value stored in the private state; nil is returned if it isn't set.
+func getScriptPrivate(ctx context.Context, private privateStateGetter) (any, diag.Diagnostics) {
+	v, diags := getPrivateValue[any](ctx, private, scriptPrivateKey)
+	if v == nil {
+		return nil, diags
+	}
+
+	return *v, diags
+}
+
+// setScriptPrivate stores the __private value returned by a command in the private state; the stored value is kept if
+// the command didn't return one.
+func setScriptPrivate(ctx context.Context, private privateStateSetter, value any) diag.Diagnostics {
+	if value == nil {
+		return nil
+	}
+
+	return setPrivateValue(ctx, private, scriptPrivateKey, value)
+}
+
+// modifyImportPlan runs the import command for a pending import and plans the output from the result.
+func (r *ScriptResource) modifyImportPlan(ctx context.Context, importID string, commands CRUDCommandsModel, plan *ScriptResourceModel, resp *resource.ModifyPlanResponse) {
+	if commands.Import == nil {
+		resp.Diagnostics.AddAttributeError(path.Root("os_commands"), "Import command is required.", "expected import to be set in os_commands to import the resource")
+		return
+	}
+
+	timeout, diags := plan.Timeouts.Read(ctx, r.providerData.DefaultTimeouts.Read)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := r.runOptions(ctx, plan, *commands.Import)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+	opts.Lifecycle = script.LifecycleImport
+	opts.ImportID = importID
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := r.runner.Run(ctx, opts)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	om, ok := res.Output.(map[string]any)
+	if !ok {
+		resp.Diagnostics.AddError("Invalid import output.", fmt.Sprintf("expected a JSON object with inputs and output keys, got: %T", res.Output))
+		return
+	}
+
+	imported := importResult{
+		Inputs: om["inputs"],
+		Output: om["output"],
+	}
+
+	if resp.Diagnostics.Append(setPrivateValue(ctx, resp.Private, importResultPrivateKey, imported)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The imported output is only valid if the update command doesn't need to reconcile the inputs.
+	match, err := jsonEqual(imported.Inputs, opts.Inputs)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to compare the imported inputs.", err.Error())
+		return
+	}
+
+	if match {
+		res := script.GetRunCommandResult(imported.Output)
+		out, sensitiveOut, diags := decodeOutput(ctx, res.Output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, plan.OutputType))
+		if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 			return
 		}
 		plan.Output = out
+		plan.OutputSensitive = sensitiveOut
+	} else {
+		plan.Output = types.DynamicUnknown()
+		plan.OutputSensitive = types.DynamicUnknown()
 	}
 
 	plan.OutputDrift = types.BoolValue(false)
 
-	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
 }
 
 // Create creates the resource.
@@ -386,46 +915,49 @@ func (r *ScriptResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	inputs, err := tfdynamic.EncodeDynamic(ctx, plan.Inputs)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+	opts, diags := r.runOptions(ctx, &plan, command.Create)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Lifecycle = script.LifecycleCreate
+	opts.ReadPartialOutput = true
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	interpreter, diags := resolveInterpreter(ctx, command.Create.Interpreter, r.providerData.DefaultInterpreter)
-	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+	// Write-only values are only available from the config.
+	var tfInputsWO types.Dynamic
+	if resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inputs_wo"), &tfInputsWO)...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	environment, diags := resolveEnvironment(ctx, plan.Environment, r.providerData.Environment)
-	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+	inputsWO, err := tfdynamic.EncodeDynamic(ctx, tfInputsWO)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode the write-only inputs.", err.Error())
 		return
 	}
+	opts.WriteOnlyInputs = inputsWO
 
-	res, diags := r.runner.Run(ctx, script.RunOptions{
-		Interpreter:      interpreter,
-		Environment:      environment,
-		WorkingDirectory: plan.WorkingDirectory.ValueString(),
-		Command:          command.Create.Command.ValueString(),
-		Lifecycle:        script.LifecycleCreate,
-		Inputs:           inputs,
-		ReadJSON:         true,
-	})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := r.runner.Run(ctx, opts)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		if res.Output != nil {
+			r.setPartialState(ctx, res, &plan, resp)
+		}
 		return
 	}
 
-	out, diags := tfdynamic.Decode(ctx, res.Output)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	out, sensitiveOut, diags := decodeOutput(ctx, res.Output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, plan.OutputType))
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 	plan.Output = out
+	plan.OutputSensitive = sensitiveOut
 	plan.OutputDrift = types.BoolValue(false)
 
+	if resp.Diagnostics.Append(setScriptPrivate(ctx, resp.Private, res.Private)...); resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -436,6 +968,16 @@ func (r *ScriptResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
+	// A pending import has no commands until it has been planned with the configuration.
+	importID, diags := getPrivateValue[string](ctx, req.Private, importIDPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if importID != nil {
+		return
+	}
+
 	var commands map[string]CRUDCommandsModel
 	if resp.Diagnostics.Append(state.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
 		return
@@ -452,58 +994,55 @@ func (r *ScriptResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	inputs, err := tfdynamic.EncodeDynamic(ctx, state.Inputs)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+	opts, diags := r.runOptions(ctx, &state, command.Read)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Lifecycle = script.LifecycleRead
 
-	stateOutput, err := tfdynamic.EncodeDynamic(ctx, state.Output)
+	stateOutput, err := encodeStateOutput(ctx, state.Output, state.OutputSensitive)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode the state output.", err.Error())
 		return
 	}
+	opts.StateOutput = stateOutput
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	interpreter, diags := resolveInterpreter(ctx, command.Read.Interpreter, r.providerData.DefaultInterpreter)
+	private, diags := getScriptPrivate(ctx, req.Private)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Private = private
 
-	environment, diags := resolveEnvironment(ctx, state.Environment, r.providerData.Environment)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := r.runner.Run(ctx, opts)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	res, diags := r.runner.Run(ctx, script.RunOptions{
-		Interpreter:      interpreter,
-		Environment:      environment,
-		WorkingDirectory: state.WorkingDirectory.ValueString(),
-		Command:          command.Read.Command.ValueString(),
-		Lifecycle:        script.LifecycleRead,
-		Inputs:           inputs,
-		StateOutput:      stateOutput,
-		ReadJSON:         true,
-	})
+	out, sensitiveOut, diags := decodeOutput(ctx, res.Output, state.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, state.OutputCollectionMode, state.OutputType))
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	state.Output = out
+	state.OutputSensitive = sensitiveOut
+	state.OutputDrift = types.BoolValue(res.Meta.OutputDriftDetected)
 
-	out, diags := tfdynamic.Decode(ctx, res.Output)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	if resp.Diagnostics.Append(setScriptPrivate(ctx, resp.Private, res.Private)...); resp.Diagnostics.HasError() {
 		return
 	}
-	state.Output = out
-	state.OutputDrift = types.BoolValue(res.Meta.OutputDriftDetected)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 // Update updates the resource.
 func (r *ScriptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// The operation context is planned in case the change becomes a replacement, so it isn't kept by an update.
+	if resp.Diagnostics.Append(resp.Private.SetKey(ctx, operationContextPrivateKey, nil)...); resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan ScriptResourceModel
 	if resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...); resp.Diagnostics.HasError() {
 		return
@@ -525,58 +1064,103 @@ func (r *ScriptResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	inputs, err := tfdynamic.EncodeDynamic(ctx, plan.Inputs)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+	opts, diags := r.runOptions(ctx, &plan, command.Update)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Lifecycle = script.LifecycleUpdate
 
 	var state ScriptResourceModel
 	if resp.Diagnostics.Append(req.State.Get(ctx, &state)...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	stateOutput, err := tfdynamic.EncodeDynamic(ctx, state.Output)
+	stateOutput, err := encodeStateOutput(ctx, state.Output, state.OutputSensitive)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode the state output.", err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	interpreter, diags := resolveInterpreter(ctx, command.Update.Interpreter, r.providerData.DefaultInterpreter)
+	imported, diags := getPrivateValue[importResult](ctx, req.Private, importResultPrivateKey)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	environment, diags := resolveEnvironment(ctx, plan.Environment, r.providerData.Environment)
+	if imported != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, importIDPrivateKey, nil)...)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, importResultPrivateKey, nil)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		match, err := jsonEqual(imported.Inputs, opts.Inputs)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to compare the imported inputs.", err.Error())
+			return
+		}
+
+		// If the imported inputs match the configuration there is nothing for the update command to reconcile.
+		if match {
+			res := script.GetRunCommandResult(imported.Output)
+			out, sensitiveOut, diags := decodeOutput(ctx, res.Output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, plan.OutputType))
+			if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+				return
+			}
+			plan.Output = out
+			plan.OutputSensitive = sensitiveOut
+			plan.OutputDrift = types.BoolValue(false)
+
+			if resp.Diagnostics.Append(setScriptPrivate(ctx, resp.Private, res.Private)...); resp.Diagnostics.HasError() {
+				return
+			}
+
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+
+		stateOutput = imported.Output
+	}
+	opts.StateOutput = stateOutput
+
+	private, diags := getScriptPrivate(ctx, req.Private)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Private = private
+
+	// Write-only values are only available from the config.
+	var tfInputsWO types.Dynamic
+	if resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inputs_wo"), &tfInputsWO)...); resp.Diagnostics.HasError() {
+		return
+	}
 
-	res, diags := r.runner.Run(ctx, script.RunOptions{
-		Interpreter:      interpreter,
-		Environment:      environment,
-		WorkingDirectory: plan.WorkingDirectory.ValueString(),
-		Command:          command.Update.Command.ValueString(),
-		Lifecycle:        script.LifecycleUpdate,
-		Inputs:           inputs,
-		StateOutput:      stateOutput,
-		ReadJSON:         true,
-	})
+	inputsWO, err := tfdynamic.EncodeDynamic(ctx, tfInputsWO)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode the write-only inputs.", err.Error())
+		return
+	}
+	opts.WriteOnlyInputs = inputsWO
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, diags := r.runner.Run(ctx, opts)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 
-	out, diags := tfdynamic.Decode(ctx, res.Output)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	out, sensitiveOut, diags := decodeOutput(ctx, res.Output, plan.SensitiveOutput, res.Meta.SensitivePaths, outputDecodeOptions(r.providerData, plan.OutputCollectionMode, plan.OutputType))
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
 	plan.Output = out
+	plan.OutputSensitive = sensitiveOut
 	plan.OutputDrift = types.BoolValue(false)
 
+	if resp.Diagnostics.Append(setScriptPrivate(ctx, resp.Private, res.Private)...); resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -587,6 +1171,26 @@ func (r *ScriptResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	importID, diags := getPrivateValue[string](ctx, req.Private, importIDPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if importID != nil {
+		resp.Diagnostics.AddError("Resource import has not been applied.", fmt.Sprintf("the resource imported with ID %q has no commands in state; apply the configuration before deleting it or remove it from state", *importID))
+		return
+	}
+
+	plannedContext, diags := getPrivateValue[script.OperationContext](ctx, req.Private, operationContextPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	operationContext := script.OperationContextDestroy
+	if plannedContext != nil {
+		operationContext = *plannedContext
+	}
+
 	var commands map[string]CRUDCommandsModel
 	if resp.Diagnostics.Append(state.OSCommands.ElementsAs(ctx, &commands, false)...); resp.Diagnostics.HasError() {
 		return
@@ -603,42 +1207,42 @@ func (r *ScriptResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	inputs, err := tfdynamic.EncodeDynamic(ctx, state.Inputs)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode the inputs.", err.Error())
+	opts, diags := r.runOptions(ctx, &state, command.Delete)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Lifecycle = script.LifecycleDelete
+	opts.OperationContext = operationContext
+	opts.ReadJSON = false
 
-	stateOutput, err := tfdynamic.EncodeDynamic(ctx, state.Output)
+	stateOutput, err := encodeStateOutput(ctx, state.Output, state.OutputSensitive)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode the state output.", err.Error())
 		return
 	}
+	opts.StateOutput = stateOutput
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	interpreter, diags := resolveInterpreter(ctx, command.Delete.Interpreter, r.providerData.DefaultInterpreter)
+	private, diags := getScriptPrivate(ctx, req.Private)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+	opts.Private = private
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	environment, diags := resolveEnvironment(ctx, state.Environment, r.providerData.Environment)
+	_, diags = r.runner.Run(ctx, opts)
 	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	_, diags = r.runner.Run(ctx, script.RunOptions{
-		Interpreter:      interpreter,
-		Environment:      environment,
-		WorkingDirectory: state.WorkingDirectory.ValueString(),
-		Command:          command.Delete.Command.ValueString(),
-		Lifecycle:        script.LifecycleDelete,
-		Inputs:           inputs,
-		StateOutput:      stateOutput,
-		ReadJSON:         false,
-	})
-	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+// ImportState imports the resource.
+func (r *ScriptResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The import command is only available from the configuration, so it runs when the resource is next planned.
+	if resp.Diagnostics.Append(setPrivateValue(ctx, resp.Private, importIDPrivateKey, req.ID)...); resp.Diagnostics.HasError() {
 		return
 	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("output_drift"), true)...)
 }
@@ -15,7 +15,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccScriptResource(t *testing.T) {
@@ -301,7 +303,7 @@ resource "shell_script" "test" {
 		})
 	})
 
-	t.Run("create_with_triggers", func(t *testing.T) {
+	t.Run("create_with_sensitive", func(t *testing.T) {
 		t.Parallel()
 
 		resource.Test(t, resource.TestCase{
@@ -311,27 +313,33 @@ resource "shell_script" "test" {
 				{
 					Config: `
 resource "shell_script" "test" {
-  triggers = {
-    gen = 1
+  sensitive_environment = {
+    MY_SECRET = "my-secret"
+  }
+  sensitive_inputs = {
+    token = "my-token"
   }
   os_commands = {
     default = {
       create = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_SENSITIVE_INPUTS}")"
+          printf '{"secret": "%s", "token": "%s"}' "$${MY_SECRET}" "$${token}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_SENSITIVE_INPUTS}")"
+          printf '{"secret": "%s", "token": "%s"}' "$${MY_SECRET}" "$${token}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          token="$(jq --raw-output '.token' <<<"$${TF_SCRIPT_SENSITIVE_INPUTS}")"
+          printf '{"secret": "%s", "token": "%s"}' "$${MY_SECRET}" "$${token}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
@@ -341,17 +349,20 @@ resource "shell_script" "test" {
     windows = {
       create = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_SENSITIVE_INPUTS | ConvertFrom-Json
+          @{secret=$env:MY_SECRET; token=$inputs.token} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_SENSITIVE_INPUTS | ConvertFrom-Json
+          @{secret=$env:MY_SECRET; token=$inputs.token} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_SENSITIVE_INPUTS | ConvertFrom-Json
+          @{secret=$env:MY_SECRET; token=$inputs.token} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
@@ -362,62 +373,39 @@ resource "shell_script" "test" {
 }
 `,
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"secret": knownvalue.StringExact("my-secret"), "token": knownvalue.StringExact("my-token")})),
 					},
 				},
 			},
 		})
 	})
 
-	t.Run("create_with_plan", func(t *testing.T) {
+	t.Run("create_with_sensitive_output", func(t *testing.T) {
 		t.Parallel()
 
-		v := "my-value"
-
 		resource.Test(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 			Steps: []resource.TestStep{
 				{
-					ConfigVariables: map[string]config.Variable{
-						"value": config.StringVariable(v),
-					},
 					Config: `
-variable "value" {
-  type = string
-}
 resource "shell_script" "test" {
-  inputs = {
-    value = var.value
-  }
+  sensitive_output = true
   os_commands = {
     default = {
-      plan = {
-        command = <<-EOF
-          set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
-          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
-        EOF
-      }
       create = {
         command = <<-EOF
-          set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
-          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          printf '{"password": "my-password"}' > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
-          set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
-          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
-          set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
-          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
@@ -425,28 +413,19 @@ resource "shell_script" "test" {
       }
     }
     windows = {
-      plan = {
-        command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-        EOF
-      }
       create = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          '{"password": "my-password"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
@@ -456,21 +435,16 @@ resource "shell_script" "test" {
   }
 }
 `,
-
-					ConfigPlanChecks: resource.ConfigPlanChecks{
-						PreApply: []plancheck.PlanCheck{
-							plancheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact(v)})),
-						},
-					},
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact(v)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.Null()),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output_sensitive"), knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-password")})),
 					},
 				},
 			},
 		})
 	})
 
-	t.Run("create_with_plan_unknown", func(t *testing.T) {
+	t.Run("create_with_sensitive_paths", func(t *testing.T) {
 		t.Parallel()
 
 		resource.Test(t, resource.TestCase{
@@ -482,69 +456,109 @@ resource "shell_script" "test" {
 resource "shell_script" "test" {
   os_commands = {
     default = {
-      plan = {
+      create = {
+        command = <<-EOF
+          printf '{"user": "admin", "auth": {"password": "my-password", "type": "basic"}, "__meta": {"sensitive_paths": ["auth.password"]}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
         command = <<-EOF
           set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_STATE_OUTPUT:-}")"
-          if [[ -z "$${value}" ]]; then
-            printf '{"value": "???"}' > "$${TF_SCRIPT_OUTPUT}"
-          else
-            printf '{"value": %d}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
-          fi
+          jq '. + {"__meta": {"sensitive_paths": ["auth.password"]}}' <<<"$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
-      create = {
+      update = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"value": %d}' "$(date +%s)" > "$${TF_SCRIPT_OUTPUT}"
+          jq '. + {"__meta": {"sensitive_paths": ["auth.password"]}}' <<<"$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"user": "admin", "auth": {"password": "my-password", "type": "basic"}, "__meta": {"sensitive_paths": ["auth.password"]}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          set -euo pipefail
-          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
-          printf '{"value": %d}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          $output = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $output | Add-Member -NotePropertyName __meta -NotePropertyValue @{sensitive_paths=@("auth.password")}
+          $output | ConvertTo-Json -Compress -Depth 10 | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          set -euo pipefail
-          printf '{"value": %d}' "$(date +%s)" > "$${TF_SCRIPT_OUTPUT}"
+          $output = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $output | Add-Member -NotePropertyName __meta -NotePropertyValue @{sensitive_paths=@("auth.password")}
+          $output | ConvertTo-Json -Compress -Depth 10 | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
         command = ""
       }
     }
-    windows = {
-      plan = {
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"user": knownvalue.StringExact("admin"), "auth": knownvalue.ObjectExact(map[string]knownvalue.Check{"type": knownvalue.StringExact("basic")})})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output_sensitive"), knownvalue.ObjectExact(map[string]knownvalue.Check{"auth": knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-password")})})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_indexed_sensitive_paths", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  os_commands = {
+    default = {
+      create = {
         command = <<-EOF
-          if ($env:TF_SCRIPT_STATE_OUTPUT) {
-            $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
-            $value = $state.value
-            @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          } else {
-            @{value="???"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          }
+          printf '{"tokens": ["a", "b"], "__meta": {"sensitive_paths": ["tokens[0]"]}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '. + {"__meta": {"sensitive_paths": ["tokens[0]"]}}' <<<"$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '. + {"__meta": {"sensitive_paths": ["tokens[0]"]}}' <<<"$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
       create = {
         command = <<-EOF
-          $value = [int64] (Get-Date -UFormat "%s")
-          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          '{"tokens": ["a", "b"], "__meta": {"sensitive_paths": ["tokens[0]"]}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
-          $value = $state.value
-          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $output = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $output | Add-Member -NotePropertyName __meta -NotePropertyValue @{sensitive_paths=@("tokens[0]")}
+          $output | ConvertTo-Json -Compress -Depth 10 | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          $value = [int64] (Get-Date -UFormat "%s")
-          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $output = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $output | Add-Member -NotePropertyName __meta -NotePropertyValue @{sensitive_paths=@("tokens[0]")}
+          $output | ConvertTo-Json -Compress -Depth 10 | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
@@ -553,83 +567,106 @@ resource "shell_script" "test" {
     }
   }
 }
-`,
+`
+
+		stateChecks := []statecheck.StateCheck{
+			statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"tokens": knownvalue.ListExact([]knownvalue.Check{knownvalue.Null(), knownvalue.StringExact("b")})})),
+			statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output_sensitive"), knownvalue.ObjectExact(map[string]knownvalue.Check{"tokens": knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("a"), knownvalue.Null()})})),
+		}
 
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config:            config,
+					ConfigStateChecks: stateChecks,
+				},
+				{
+					RefreshState: true,
+				},
+				{
+					Config: config,
 					ConfigPlanChecks: resource.ConfigPlanChecks{
 						PreApply: []plancheck.PlanCheck{
-							plancheck.ExpectUnknownValue("shell_script.test", tfjsonpath.New("output").AtMapKey("value")),
+							plancheck.ExpectEmptyPlan(),
 						},
 					},
-					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.NumberFunc(func(_ *big.Float) error { return nil })})),
-					},
+					ConfigStateChecks: stateChecks,
 				},
 			},
 		})
 	})
 
-	t.Run("create_with_log_output", func(t *testing.T) {
+	t.Run("create_with_inputs_wo", func(t *testing.T) {
 		t.Parallel()
 
 		resource.Test(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_11_0),
+			},
 			Steps: []resource.TestStep{
 				{
 					Config: `
 resource "shell_script" "test" {
+  inputs_wo = {
+    password = "my-password"
+  }
+  inputs_wo_version = 1
   os_commands = {
     default = {
       create = {
         command = <<-EOF
           set -euo pipefail
-          echo "[INFO] create"
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          password="$(jq --raw-output '.password' <<<"$${TF_SCRIPT_INPUTS_WO}")"
+          printf '{"password": "%s"}' "$${password}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
           set -euo pipefail
-          echo "[INFO] read"
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          [[ -z "$${TF_SCRIPT_INPUTS_WO:-}" ]] || exit 1
+          printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
           set -euo pipefail
-          echo "[INFO] update"
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          password="$(jq --raw-output '.password' <<<"$${TF_SCRIPT_INPUTS_WO}")"
+          printf '{"password": "%s"}' "$${password}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
         command = <<-EOF
           set -euo pipefail
-          echo "[INFO] delete"
+          [[ -z "$${TF_SCRIPT_INPUTS_WO:-}" ]] || exit 1
         EOF
       }
     }
     windows = {
       create = {
         command = <<-EOF
-          Write-Output "[INFO] create"
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS_WO | ConvertFrom-Json
+          @{password=$inputs.password} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          Write-Output "[INFO] read"
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          if ($env:TF_SCRIPT_INPUTS_WO) { exit 1 }
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          Write-Output "[INFO] update"
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS_WO | ConvertFrom-Json
+          @{password=$inputs.password} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
         command = <<-EOF
-          Write-Output "[INFO] delete"
+          if ($env:TF_SCRIPT_INPUTS_WO) { exit 1 }
         EOF
       }
     }
@@ -637,253 +674,2054 @@ resource "shell_script" "test" {
 }
 `,
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("inputs_wo"), knownvalue.Null()),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("inputs_wo_version"), knownvalue.Int64Exact(1)),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-password")})),
 					},
 				},
-			},
-		})
-	})
-
-	t.Run("update", func(t *testing.T) {
-		t.Parallel()
-
-		file := acctest.RandomWithPrefix("tf-script-test")
-		newFile := acctest.RandomWithPrefix("tf-script-test-new")
-		config := `
+				{
+					Config: `
 resource "shell_script" "test" {
-  inputs = {
-    file_name = "%s"
+  inputs_wo = {
+    password = "my-new-password"
   }
+  inputs_wo_version = 2
   os_commands = {
     default = {
       create = {
         command = <<-EOF
           set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          touch "$${path}"
-          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          password="$(jq --raw-output '.password' <<<"$${TF_SCRIPT_INPUTS_WO}")"
+          printf '{"password": "%s"}' "$${password}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
           set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          if [[ -f "$${path}" ]]; then
-            printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
-          else
-            printf '{"exists": false,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
-          fi
+          [[ -z "$${TF_SCRIPT_INPUTS_WO:-}" ]] || exit 1
+          printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
           set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          old_path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
-          if [[ "$${path}" != "$${old_path}" ]] && [[ -f "$${old_path}" ]]; then
-            mv -f "$${old_path}" "$${path}"
-          else
-            touch "$${path}"
-          fi
-          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          password="$(jq --raw-output '.password' <<<"$${TF_SCRIPT_INPUTS_WO}")"
+          printf '{"password": "%s"}' "$${password}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
         command = <<-EOF
           set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          rm -f "$${path}"
+          [[ -z "$${TF_SCRIPT_INPUTS_WO:-}" ]] || exit 1
         EOF
       }
     }
     windows = {
       create = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          New-Item -Path $path -ItemType File -Force
-          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS_WO | ConvertFrom-Json
+          @{password=$inputs.password} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          if (Test-Path $path) {
-            @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          } else {
-            @{exists=$false; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          }
+          if ($env:TF_SCRIPT_INPUTS_WO) { exit 1 }
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
-          $oldPath = $state.path
-          if ($path -ne $oldPath) {
-            Move-Item -Path $oldPath -Destination $path -Force
-          } else {
-            New-Item -Path $path -ItemType File -Force
-          }
-          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS_WO | ConvertFrom-Json
+          @{password=$inputs.password} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          Remove-Item -Path $path -Force
+          if ($env:TF_SCRIPT_INPUTS_WO) { exit 1 }
         EOF
       }
     }
   }
 }
-`
-
-		resource.Test(t, resource.TestCase{
-			PreCheck:                 func() { testAccPreCheck(t) },
-			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
-			Steps: []resource.TestStep{
-				{
-					Config: fmt.Sprintf(config, file),
-					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(".+tf-script-test")), "exists": knownvalue.Bool(true)})),
-					},
-				},
-				{
-					Config: fmt.Sprintf(config, newFile),
+`,
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(".+tf-script-test-new")), "exists": knownvalue.Bool(true)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("inputs_wo"), knownvalue.Null()),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("inputs_wo_version"), knownvalue.Int64Exact(2)),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"password": knownvalue.StringExact("my-new-password")})),
 					},
 				},
 			},
 		})
 	})
 
-	t.Run("update_external", func(t *testing.T) {
+	t.Run("create_with_retry", func(t *testing.T) {
 		t.Parallel()
 
-		file := acctest.RandomWithPrefix("tf-script-test")
-		config := fmt.Sprintf(`
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          if [[ ! -f "$${path}" ]]; then
+            touch "$${path}"
+            printf 'service unavailable' > "$${TF_SCRIPT_ERROR}"
+            exit 75
+          fi
+          printf '{"success": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          printf '{"success": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          printf '{"success": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          if (-not (Test-Path $inputs.path)) {
+            New-Item -ItemType File -Path $inputs.path | Out-Null
+            'service unavailable' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8
+            exit 75
+          }
+          '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"success": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+  retry = {
+    max_attempts            = 3
+    initial_backoff         = "10ms"
+    multiplier              = 1.5
+    jitter                  = 0.1
+    retryable_error_pattern = "unavailable"
+  }
+}
+`, file),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"success": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_output_source_stdout", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"run": true}'`
+		if runtime.GOOS == "windows" {
+			cmd = `Write-Output '{"run": true}'`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+resource "shell_script" "test" {
+  output_source = "stdout"
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          %[1]s
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          %[1]s
+        EOF
+      }
+      delete = {
+        command = "echo 'not json'"
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_output_type", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := `printf '{"id": 123, "names": ["b", "a", "b"]}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			cmd = `'{"id": 123, "names": ["b", "a", "b"]}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+resource "shell_script" "test" {
+  output_type = "object({id=string, names=set(string), note=optional(string)})"
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          %[1]s
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          %[1]s
+        EOF
+      }
+      delete = {
+        command = "exit 0"
+      }
+    }
+  }
+}
+`, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"id":    knownvalue.StringExact("123"),
+							"names": knownvalue.SetExact([]knownvalue.Check{knownvalue.StringExact("a"), knownvalue.StringExact("b")}),
+							"note":  knownvalue.Null(),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_schemas", func(t *testing.T) {
+		t.Parallel()
+
+		planCmd := `if [[ -n "$${TF_SCRIPT_STATE_OUTPUT:-}" ]]; then printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}"; else printf '{"id": "???"}'; fi > "$${TF_SCRIPT_OUTPUT}"`
+		cmd := `printf '{"id": "abc"}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			planCmd = `if ($env:TF_SCRIPT_STATE_OUTPUT) { $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8 } else { '{"id": "???"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8 }`
+			cmd = `'{"id": "abc"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+resource "terraform_data" "test" {
+  input = "test"
+}
+
+resource "shell_script" "test" {
+  inputs_schema = jsonencode({
+    type       = "object"
+    required   = ["name"]
+    properties = { name = { type = "string" } }
+  })
+  output_schema = jsonencode({
+    type       = "object"
+    properties = { id = { type = "string", pattern = "^[a-z]+$" } }
+  })
+  inputs = {
+    name = terraform_data.test.output
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          %[2]s
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          %[2]s
+        EOF
+      }
+      delete = {
+        command = "exit 0"
+      }
+    }
+  }
+}
+`, planCmd, cmd),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"id": knownvalue.StringExact("abc")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_triggers", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+resource "shell_script" "test" {
+  triggers = {
+    gen = 1
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_plan", func(t *testing.T) {
+		t.Parallel()
+
+		v := "my-value"
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					ConfigVariables: map[string]config.Variable{
+						"value": config.StringVariable(v),
+					},
+					Config: `
+variable "value" {
+  type = string
+}
+resource "shell_script" "test" {
+  inputs = {
+    value = var.value
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"value": "%s"}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{value=$inputs.value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`,
+
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact(v)})),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.StringExact(v)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_plan_unknown", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+resource "shell_script" "test" {
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_STATE_OUTPUT:-}")"
+          if [[ -z "$${value}" ]]; then
+            printf '{"value": "???"}' > "$${TF_SCRIPT_OUTPUT}"
+          else
+            printf '{"value": %d}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+          fi
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"value": %d}' "$(date +%s)" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          value="$(jq --raw-output '.value' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
+          printf '{"value": %d}' "$${value}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"value": %d}' "$(date +%s)" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          if ($env:TF_SCRIPT_STATE_OUTPUT) {
+            $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+            $value = $state.value
+            @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          } else {
+            @{value="???"} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          }
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $value = [int64] (Get-Date -UFormat "%s")
+          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $value = $state.value
+          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $value = [int64] (Get-Date -UFormat "%s")
+          @{value=$value} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`,
+
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectUnknownValue("shell_script.test", tfjsonpath.New("output").AtMapKey("value")),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"value": knownvalue.NumberFunc(func(_ *big.Float) error { return nil })})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_plan_unknown_paths", func(t *testing.T) {
+		t.Parallel()
+
+		planCmd := `if [[ -n "$${TF_SCRIPT_STATE_OUTPUT:-}" ]]; then printf '%s' "$${TF_SCRIPT_STATE_OUTPUT}"; else printf '{"name": "???", "endpoints": [{"ip": ""}], "__meta": {"unknown_paths": ["id", "endpoints[0].ip"]}}'; fi > "$${TF_SCRIPT_OUTPUT}"`
+		cmd := `printf '{"name": "???", "id": "abc", "endpoints": [{"ip": "10.0.0.1"}]}' > "$${TF_SCRIPT_OUTPUT}"`
+		if runtime.GOOS == "windows" {
+			planCmd = `if ($env:TF_SCRIPT_STATE_OUTPUT) { $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8 } else { '{"name": "???", "endpoints": [{"ip": ""}], "__meta": {"unknown_paths": ["id", "endpoints[0].ip"]}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8 }`
+			cmd = `'{"name": "???", "id": "abc", "endpoints": [{"ip": "10.0.0.1"}]}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+provider "shell" {
+  unknown_string_literal = false
+}
+
+resource "shell_script" "test" {
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          %s
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          %[2]s
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          %[2]s
+        EOF
+      }
+      delete = {
+        command = "exit 0"
+      }
+    }
+  }
+}
+`, planCmd, cmd),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output").AtMapKey("name"), knownvalue.StringExact("???")),
+							plancheck.ExpectUnknownValue("shell_script.test", tfjsonpath.New("output").AtMapKey("id")),
+							plancheck.ExpectUnknownValue("shell_script.test", tfjsonpath.New("output").AtMapKey("endpoints").AtSliceIndex(0).AtMapKey("ip")),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"name":      knownvalue.StringExact("???"),
+							"id":        knownvalue.StringExact("abc"),
+							"endpoints": knownvalue.TupleExact([]knownvalue.Check{knownvalue.ObjectExact(map[string]knownvalue.Check{"ip": knownvalue.StringExact("10.0.0.1")})}),
+						})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("create_with_log_output", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+resource "shell_script" "test" {
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          echo "[INFO] create"
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          echo "[INFO] read"
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          echo "[INFO] update"
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          echo "[INFO] delete"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          Write-Output "[INFO] create"
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          Write-Output "[INFO] read"
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          Write-Output "[INFO] update"
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          Write-Output "[INFO] delete"
+        EOF
+      }
+    }
+  }
+}
+`,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+
+		file := acctest.RandomWithPrefix("tf-script-test")
+		newFile := acctest.RandomWithPrefix("tf-script-test-new")
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    file_name = "%s"
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          touch "$${path}"
+          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          if [[ -f "$${path}" ]]; then
+            printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          else
+            printf '{"exists": false,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          fi
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          old_path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
+          if [[ "$${path}" != "$${old_path}" ]] && [[ -f "$${old_path}" ]]; then
+            mv -f "$${old_path}" "$${path}"
+          else
+            touch "$${path}"
+          fi
+          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          rm -f "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          New-Item -Path $path -ItemType File -Force
+          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          if (Test-Path $path) {
+            @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          } else {
+            @{exists=$false; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          }
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $oldPath = $state.path
+          if ($path -ne $oldPath) {
+            Move-Item -Path $oldPath -Destination $path -Force
+          } else {
+            New-Item -Path $path -ItemType File -Force
+          }
+          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          Remove-Item -Path $path -Force
+        EOF
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, file),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(".+tf-script-test")), "exists": knownvalue.Bool(true)})),
+					},
+				},
+				{
+					Config: fmt.Sprintf(config, newFile),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(".+tf-script-test-new")), "exists": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_external", func(t *testing.T) {
+		t.Parallel()
+
+		file := acctest.RandomWithPrefix("tf-script-test")
+		config := fmt.Sprintf(`
+resource "shell_script" "test" {
+  inputs = {
+    file_name = "%s"
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          touch "$${path}"
+          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          if [[ -f "$${path}" ]]; then
+            printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          else
+            printf '{"exists": false,"path":"%%s","__meta":{"output_drift_detected":true}}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          fi
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          old_path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
+          if [[ "$${path}" != "$${old_path}" ]] && [[ -f "$${old_path}" ]]; then
+            mv -f "$${old_path}" "$${path}"
+          else
+            touch "$${path}"
+          fi
+          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          path="/tmp/$${file_name}"
+          rm -f "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          New-Item -Path $path -ItemType File -Force
+          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          if (Test-Path $path) {
+            @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          } else {
+            @{exists=$false; path=$path; __meta=@{output_drift_detected=$true}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          }
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+          $oldPath = $state.path
+          if ($path -ne $oldPath) {
+            Move-Item -Path $oldPath -Destination $path -Force
+          } else {
+            New-Item -Path $path -ItemType File -Force
+          }
+          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $fileName = $inputs.file_name
+          $path = "$env:TEMP\$fileName"
+          Remove-Item -Path $path -Force -ErrorAction Ignore
+        EOF
+      }
+    }
+  }
+}
+`, file)
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: config,
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(fmt.Sprintf(".+%s$", file))), "exists": knownvalue.Bool(true)})),
+					},
+				},
+				{
+					PreConfig: func() {
+						if err := os.Remove(path.Join(os.TempDir(), file)); err != nil {
+							t.Fatalf("failed to remove file: %s", err)
+						}
+					},
+					Config: config,
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectNonEmptyPlan(),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(fmt.Sprintf(".+%s$", file))), "exists": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_triggers", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  triggers = {
+    gen = %d
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, 0),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+					},
+				},
+				{
+					Config: fmt.Sprintf(config, 1),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_requires_replace", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    region = "%s"
+    size   = %d
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, size: .size, __meta: {requires_replace: ["region"]}}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; size=$inputs.size; __meta=@{requires_replace=@("region")}} | ConvertTo-Json -Compress -Depth 5 | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, "a", 1),
+				},
+				{
+					Config: fmt.Sprintf(config, "a", 2),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionUpdate),
+						},
+					},
+				},
+				{
+					Config: fmt.Sprintf(config, "b", 2),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"region": knownvalue.StringExact("b"), "size": knownvalue.Int64Exact(2)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_requires_replace_all_unchanged_output", func(t *testing.T) {
+		t.Parallel()
+
+		// The plan command keeps the state output, so the output path doesn't change when the replacement is requested.
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    region = "%s"
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          jq -n --argjson i "$${TF_SCRIPT_INPUTS}" --argjson s "$${TF_SCRIPT_STATE_OUTPUT:-null}" \
+            'if $s == null then {region: $i.region} else $s + {__meta: {requires_replace: ($s.region != $i.region)}} end' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          printf '%%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = "exit 1"
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          if ($env:TF_SCRIPT_STATE_OUTPUT) {
+            $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+            $output = @{region=$state.region; __meta=@{requires_replace=($state.region -ne $inputs.region)}}
+          } else {
+            $output = @{region=$inputs.region}
+          }
+          $output | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = "exit 1"
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, "eu"),
+				},
+				{
+					Config: fmt.Sprintf(config, "us"),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"region": knownvalue.StringExact("us")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_requires_replace_all", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    gen = %d
+  }
+  os_commands = {
+    default = {
+      plan = {
+        command = <<-EOF
+          set -euo pipefail
+          jq -n --argjson i "$${TF_SCRIPT_INPUTS}" --argjson s "$${TF_SCRIPT_STATE_OUTPUT:-null}" \
+            '{gen: $i.gen, __meta: {requires_replace: ($s != null and $s.gen != $i.gen)}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{gen: .gen}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '%%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = "exit 1"
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      plan = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $replace = $false
+          if ($env:TF_SCRIPT_STATE_OUTPUT) {
+            $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
+            $replace = $state.gen -ne $inputs.gen
+          }
+          @{gen=$inputs.gen; __meta=@{requires_replace=$replace}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{gen=$inputs.gen} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $env:TF_SCRIPT_STATE_OUTPUT | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = "exit 1"
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, 1),
+				},
+				{
+					Config: fmt.Sprintf(config, 2),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"gen": knownvalue.Int64Exact(2)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_replace_triggered_by_inputs", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    region  = "a"
+    cluster = {
+      name = "%s"
+    }
+    size = %d
+  }
+  replace_triggered_by_inputs = ["region", "cluster.name"]
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, cluster: .cluster.name, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, cluster: .cluster.name, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{region: .region, cluster: .cluster.name, size: .size}' <<<"$${TF_SCRIPT_INPUTS}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; cluster=$inputs.cluster.name; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; cluster=$inputs.cluster.name; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{region=$inputs.region; cluster=$inputs.cluster.name; size=$inputs.size} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, "x", 1),
+				},
+				{
+					Config: fmt.Sprintf(config, "x", 2),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionUpdate),
+						},
+					},
+				},
+				{
+					Config: fmt.Sprintf(config, "y", 2),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"region": knownvalue.StringExact("a"), "cluster": knownvalue.StringExact("y"), "size": knownvalue.Int64Exact(2)})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("update_with_private", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+    gen  = %d
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"etag": "1", "__private": {"etag": "1"}}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '{etag: .etag}' <<<"$${TF_SCRIPT_PRIVATE}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          jq '(.etag | tonumber + 1 | tostring) as $etag | {etag: $etag, __private: {etag: $etag}}' <<<"$${TF_SCRIPT_PRIVATE}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          jq --raw-output '.etag' <<<"$${TF_SCRIPT_PRIVATE}" > "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"etag": "1", "__private": {"etag": "1"}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          $private = $env:TF_SCRIPT_PRIVATE | ConvertFrom-Json
+          @{etag=$private.etag} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          $private = $env:TF_SCRIPT_PRIVATE | ConvertFrom-Json
+          $etag = ([int]$private.etag + 1).ToString()
+          @{etag=$etag; __private=@{etag=$etag}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          $private = $env:TF_SCRIPT_PRIVATE | ConvertFrom-Json
+          [IO.File]::WriteAllText($inputs.path, $private.etag + [char]10)
+        EOF
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			CheckDestroy: func(_ *terraform.State) error {
+				by, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("expected delete command to have run: %w", err)
+				}
+
+				if string(by) != "2\n" {
+					return fmt.Errorf("expected private etag 2, got: %q", string(by))
+				}
+
+				return nil
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, file, 0),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"etag": knownvalue.StringExact("1")})),
+					},
+				},
+				{
+					Config: fmt.Sprintf(config, file, 1),
+					ConfigStateChecks: []statecheck.StateCheck{
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"etag": knownvalue.StringExact("2")})),
+					},
+				},
+			},
+		})
+	})
+
+	t.Run("delete_with_operation_context", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  triggers = {
+    gen = %d
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '%%s\n' "$${TF_SCRIPT_OPERATION_CONTEXT}" >> "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::AppendAllText($inputs.path, $env:TF_SCRIPT_OPERATION_CONTEXT + [char]10)
+        EOF
+      }
+    }
+  }
+}
+`
+
+		checkFile := func(expected string) func(*terraform.State) error {
+			return func(_ *terraform.State) error {
+				by, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("expected delete command to have run: %w", err)
+				}
+
+				if string(by) != expected {
+					return fmt.Errorf("expected operation contexts %q, got: %q", expected, string(by))
+				}
+
+				return nil
+			}
+		}
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			CheckDestroy:             checkFile("replace\ndestroy\n"),
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(config, file, 0),
+				},
+				{
+					Config: fmt.Sprintf(config, file, 1),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+						},
+					},
+					Check: checkFile("replace\n"),
+				},
+			},
+		})
+	})
+
+	t.Run("delete_with_forced_replacement_operation_context", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "terraform_data" "trigger" {
+  input = %d
+}
+
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '%%s\n' "$${TF_SCRIPT_OPERATION_CONTEXT}" >> "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::AppendAllText($inputs.path, $env:TF_SCRIPT_OPERATION_CONTEXT + [char]10)
+        EOF
+      }
+    }
+  }
+%s
+}
+`
+
+		for _, d := range []struct {
+			testName  string
+			lifecycle string
+			gen       int
+			taint     []string
+			expected  string
+		}{
+			{
+				testName: "replace_triggered_by",
+				lifecycle: `
+  lifecycle {
+    replace_triggered_by = [terraform_data.trigger]
+  }
+`,
+				gen:      1,
+				expected: "replace\n",
+			},
+			{
+				testName: "taint",
+				taint:    []string{"shell_script.test"},
+				expected: "destroy\n",
+			},
+		} {
+			t.Run(d.testName, func(t *testing.T) {
+				t.Parallel()
+
+				file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+				t.Cleanup(func() {
+					_ = os.Remove(file)
+				})
+
+				checkFile := func(expected string) func(*terraform.State) error {
+					return func(_ *terraform.State) error {
+						by, err := os.ReadFile(file)
+						if err != nil {
+							return fmt.Errorf("expected delete command to have run: %w", err)
+						}
+
+						if string(by) != expected {
+							return fmt.Errorf("expected operation contexts %q, got: %q", expected, string(by))
+						}
+
+						return nil
+					}
+				}
+
+				resource.Test(t, resource.TestCase{
+					PreCheck:                 func() { testAccPreCheck(t) },
+					ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+					TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+						tfversion.SkipBelow(tfversion.Version1_4_0),
+					},
+					CheckDestroy: checkFile(d.expected + "destroy\n"),
+					Steps: []resource.TestStep{
+						{
+							Config: fmt.Sprintf(config, 0, file, d.lifecycle),
+						},
+						{
+							Config: fmt.Sprintf(config, d.gen, file, d.lifecycle),
+							Taint:  d.taint,
+							ConfigPlanChecks: resource.ConfigPlanChecks{
+								PreApply: []plancheck.PlanCheck{
+									plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+								},
+							},
+							Check: checkFile(d.expected),
+						},
+					},
+				})
+			})
+		}
+	})
+
+	t.Run("create_with_operation_context", func(t *testing.T) {
+		t.Parallel()
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+  }
+  triggers = {
+    gen = %d
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf 'create:%%s\n' "$${TF_SCRIPT_OPERATION_CONTEXT:-}" >> "$${path}"
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf 'delete:%%s\n' "$${TF_SCRIPT_OPERATION_CONTEXT}" >> "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::AppendAllText($inputs.path, 'create:' + $env:TF_SCRIPT_OPERATION_CONTEXT + [char]10)
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::AppendAllText($inputs.path, 'delete:' + $env:TF_SCRIPT_OPERATION_CONTEXT + [char]10)
+        EOF
+      }
+    }
+  }
+%s
+}
+`
+
+		for _, d := range []struct {
+			testName  string
+			lifecycle string
+			expected  string
+		}{
+			{
+				testName: "replace",
+				expected: "create:\ndelete:replace\ncreate:\n",
+			},
+			{
+				testName: "create_before_destroy",
+				lifecycle: `
+  lifecycle {
+    create_before_destroy = true
+  }
+`,
+				expected: "create:\ncreate:\ndelete:replace\n",
+			},
+		} {
+			t.Run(d.testName, func(t *testing.T) {
+				t.Parallel()
+
+				file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+				t.Cleanup(func() {
+					_ = os.Remove(file)
+				})
+
+				checkFile := func(expected string) func(*terraform.State) error {
+					return func(_ *terraform.State) error {
+						by, err := os.ReadFile(file)
+						if err != nil {
+							return fmt.Errorf("expected create command to have run: %w", err)
+						}
+
+						if string(by) != expected {
+							return fmt.Errorf("expected commands %q, got: %q", expected, string(by))
+						}
+
+						return nil
+					}
+				}
+
+				resource.Test(t, resource.TestCase{
+					PreCheck:                 func() { testAccPreCheck(t) },
+					ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+					CheckDestroy:             checkFile(d.expected + "delete:destroy\n"),
+					Steps: []resource.TestStep{
+						{
+							Config: fmt.Sprintf(config, file, 0, d.lifecycle),
+							Check:  checkFile("create:\n"),
+						},
+						{
+							Config: fmt.Sprintf(config, file, 1, d.lifecycle),
+							ConfigPlanChecks: resource.ConfigPlanChecks{
+								PreApply: []plancheck.PlanCheck{
+									plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+								},
+							},
+							Check: checkFile(d.expected),
+						},
+					},
+				})
+			})
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		t.Parallel()
+
+		file := acctest.RandomWithPrefix("tf-script-test")
+		config := fmt.Sprintf(`
 resource "shell_script" "test" {
   inputs = {
     file_name = "%s"
   }
   os_commands = {
     default = {
-      plan = {
-        command = <<-EOF
-          set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
-        EOF
-      }
       create = {
         command = <<-EOF
           set -euo pipefail
           file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          touch "$${path}"
-          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
           set -euo pipefail
           file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          if [[ -f "$${path}" ]]; then
-            printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
-          else
-            printf '{"exists": false,"path":"%%s","__meta":{"output_drift_detected":true}}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
-          fi
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
           set -euo pipefail
           file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          old_path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_STATE_OUTPUT}")"
-          if [[ "$${path}" != "$${old_path}" ]] && [[ -f "$${old_path}" ]]; then
-            mv -f "$${old_path}" "$${path}"
-          else
-            touch "$${path}"
-          fi
-          printf '{"exists": true,"path":"%%s"}' "$${path}" > "$${TF_SCRIPT_OUTPUT}"
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
+        command = ""
+      }
+      import = {
         command = <<-EOF
           set -euo pipefail
-          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
-          path="/tmp/$${file_name}"
-          rm -f "$${path}"
+          printf '{"inputs":{"file_name":"%%s"},"output":{"file_name":"%%s"}}' "$${TF_SCRIPT_IMPORT_ID}" "$${TF_SCRIPT_IMPORT_ID}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
     }
     windows = {
-      plan = {
-        command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-        EOF
-      }
       create = {
         command = <<-EOF
           $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          New-Item -Path $path -ItemType File -Force
-          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
           $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          if (Test-Path $path) {
-            @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          } else {
-            @{exists=$false; path=$path; __meta=@{output_drift_detected=$true}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
-          }
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
           $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          $state = $env:TF_SCRIPT_STATE_OUTPUT | ConvertFrom-Json
-          $oldPath = $state.path
-          if ($path -ne $oldPath) {
-            Move-Item -Path $oldPath -Destination $path -Force
-          } else {
-            New-Item -Path $path -ItemType File -Force
-          }
-          @{exists=$true; path=$path} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
+        command = ""
+      }
+      import = {
         command = <<-EOF
-          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
-          $fileName = $inputs.file_name
-          $path = "$env:TEMP\$fileName"
-          Remove-Item -Path $path -Force -ErrorAction Ignore
+          @{inputs=@{file_name=$env:TF_SCRIPT_IMPORT_ID}; output=@{file_name=$env:TF_SCRIPT_IMPORT_ID}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
     }
@@ -898,105 +2736,126 @@ resource "shell_script" "test" {
 				{
 					Config: config,
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(fmt.Sprintf(".+%s$", file))), "exists": knownvalue.Bool(true)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"file_name": knownvalue.StringExact(file)})),
 					},
 				},
 				{
-					PreConfig: func() {
-						if err := os.Remove(path.Join(os.TempDir(), file)); err != nil {
-							t.Fatalf("failed to remove file: %s", err)
-						}
-					},
-					Config: config,
-					ConfigPlanChecks: resource.ConfigPlanChecks{
+					Config:             config,
+					ResourceName:       "shell_script.test",
+					ImportState:        true,
+					ImportStateKind:    resource.ImportBlockWithID,
+					ImportStateId:      file,
+					ExpectNonEmptyPlan: true,
+					ImportPlanChecks: resource.ImportPlanChecks{
 						PreApply: []plancheck.PlanCheck{
-							plancheck.ExpectNonEmptyPlan(),
+							plancheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"file_name": knownvalue.StringExact(file)})),
 						},
 					},
-					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"path": knownvalue.StringRegexp(regexp.MustCompile(fmt.Sprintf(".+%s$", file))), "exists": knownvalue.Bool(true)})),
-					},
 				},
 			},
 		})
 	})
 
-	t.Run("update_with_triggers", func(t *testing.T) {
+	t.Run("import_with_triggers", func(t *testing.T) {
 		t.Parallel()
 
-		config := `
+		file := acctest.RandomWithPrefix("tf-script-test")
+		config := fmt.Sprintf(`
 resource "shell_script" "test" {
+  inputs = {
+    file_name = "%s"
+  }
   triggers = {
-    gen = %d
+    gen = 1
   }
   os_commands = {
     default = {
       create = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       read = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       update = {
         command = <<-EOF
           set -euo pipefail
-          printf '{"run": true}' > "$${TF_SCRIPT_OUTPUT}"
+          file_name="$(jq --raw-output '.file_name' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '{"file_name":"%%s"}' "$${file_name}" > "$${TF_SCRIPT_OUTPUT}"
         EOF
       }
       delete = {
         command = ""
       }
+      import = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"inputs":{"file_name":"%%s"},"output":{"file_name":"%%s"}}' "$${TF_SCRIPT_IMPORT_ID}" "$${TF_SCRIPT_IMPORT_ID}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
     }
     windows = {
       create = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       read = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       update = {
         command = <<-EOF
-          '{"run": true}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          @{file_name=$inputs.file_name} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
         EOF
       }
       delete = {
         command = ""
       }
+      import = {
+        command = <<-EOF
+          @{inputs=@{file_name=$env:TF_SCRIPT_IMPORT_ID}; output=@{file_name=$env:TF_SCRIPT_IMPORT_ID}} | ConvertTo-Json -Compress | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+        EOF
+      }
     }
   }
 }
-`
+`, file)
 
 		resource.Test(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 			Steps: []resource.TestStep{
 				{
-					Config: fmt.Sprintf(config, 0),
+					Config: config,
 					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
+						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"file_name": knownvalue.StringExact(file)})),
 					},
 				},
 				{
-					Config: fmt.Sprintf(config, 1),
-					ConfigPlanChecks: resource.ConfigPlanChecks{
+					Config:             config,
+					ResourceName:       "shell_script.test",
+					ImportState:        true,
+					ImportStateKind:    resource.ImportBlockWithID,
+					ImportStateId:      file,
+					ExpectNonEmptyPlan: true,
+					ImportPlanChecks: resource.ImportPlanChecks{
 						PreApply: []plancheck.PlanCheck{
-							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionReplace),
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionUpdate),
+							plancheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"file_name": knownvalue.StringExact(file)})),
 						},
 					},
-					ConfigStateChecks: []statecheck.StateCheck{
-						statecheck.ExpectKnownValue("shell_script.test", tfjsonpath.New("output"), knownvalue.ObjectExact(map[string]knownvalue.Check{"run": knownvalue.Bool(true)})),
-					},
 				},
 			},
 		})
@@ -1045,6 +2904,41 @@ resource "shell_script" "test" {
 		})
 	})
 
+	t.Run("error_invalid_replace_triggered_by_inputs", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: `
+resource "shell_script" "test" {
+  replace_triggered_by_inputs = ["disks[a]"]
+  os_commands = {
+    default = {
+      create = {
+        command = ""
+      }
+      read = {
+        command = ""
+      }
+      update = {
+        command = ""
+      }
+      delete = {
+        command = ""
+      }
+    }
+  }
+}
+`,
+					ExpectError: regexp.MustCompile(`Invalid input path`),
+				},
+			},
+		})
+	})
+
 	t.Run("error_no_json", func(t *testing.T) {
 		t.Parallel()
 
@@ -1113,6 +3007,115 @@ resource "shell_script" "test" {
 		})
 	})
 
+	t.Run("error_create_partial_output", func(t *testing.T) {
+		t.Parallel()
+
+		file := path.Join(os.TempDir(), acctest.RandomWithPrefix("tf-script-test"))
+		t.Cleanup(func() {
+			_ = os.Remove(file)
+		})
+
+		config := `
+resource "shell_script" "test" {
+  inputs = {
+    path = %q
+    fail = %t
+  }
+  os_commands = {
+    default = {
+      create = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '{"id": "a"}' > "$${TF_SCRIPT_OUTPUT}"
+          if [[ "$(jq '.fail' <<<"$${TF_SCRIPT_INPUTS}")" == "true" ]]; then
+            exit 1
+          fi
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '%%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          set -euo pipefail
+          printf '%%s' "$${TF_SCRIPT_STATE_OUTPUT}" > "$${TF_SCRIPT_OUTPUT}"
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          set -euo pipefail
+          path="$(jq --raw-output '.path' <<<"$${TF_SCRIPT_INPUTS}")"
+          printf '%%s\n%%s' "$${TF_SCRIPT_OPERATION_CONTEXT}" "$${TF_SCRIPT_STATE_OUTPUT}" > "$${path}"
+        EOF
+      }
+    }
+    windows = {
+      create = {
+        command = <<-EOF
+          '{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          if ($inputs.fail) {
+            exit 1
+          }
+        EOF
+      }
+      read = {
+        command = <<-EOF
+          [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, $env:TF_SCRIPT_STATE_OUTPUT)
+        EOF
+      }
+      update = {
+        command = <<-EOF
+          [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, $env:TF_SCRIPT_STATE_OUTPUT)
+        EOF
+      }
+      delete = {
+        command = <<-EOF
+          $inputs = $env:TF_SCRIPT_INPUTS | ConvertFrom-Json
+          [IO.File]::WriteAllText($inputs.path, $env:TF_SCRIPT_OPERATION_CONTEXT + [char]10 + $env:TF_SCRIPT_STATE_OUTPUT)
+        EOF
+      }
+    }
+  }
+}
+`
+
+		resource.Test(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config:      fmt.Sprintf(config, file, true),
+					ExpectError: regexp.MustCompile(`Command failed with exit code: 1`),
+				},
+				{
+					Config: fmt.Sprintf(config, file, false),
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction("shell_script.test", plancheck.ResourceActionDestroyBeforeCreate),
+						},
+					},
+					Check: func(_ *terraform.State) error {
+						by, err := os.ReadFile(file)
+						if err != nil {
+							return fmt.Errorf("expected delete command to have run: %w", err)
+						}
+
+						// The tainted resource is planned without its private state, so it's deleted as destroyed.
+						if string(by) != "destroy\n"+`{"id":"a"}` {
+							return fmt.Errorf("expected destroy context and partial state output, got: %q", string(by))
+						}
+
+						return nil
+					},
+				},
+			},
+		})
+	})
+
 	t.Run("error_message", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,87 @@
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
+)
+
+const (
+	// DiagnosticSeverityError is the severity of an error diagnostic in a structured error file.
+	DiagnosticSeverityError string = "error"
+
+	// DiagnosticSeverityWarning is the severity of a warning diagnostic in a structured error file.
+	DiagnosticSeverityWarning string = "warning"
+
+	// inputsAttribute is the root attribute that diagnostic attribute paths are relative to.
+	inputsAttribute = "inputs"
+)
+
+// ErrorFile describes a structured error file written by a command.
+type ErrorFile struct {
+	Diagnostics []ErrorFileDiagnostic `json:"diagnostics"`
+}
+
+// ErrorFileDiagnostic describes a diagnostic in a structured error file.
+type ErrorFileDiagnostic struct {
+	Severity  string `json:"severity"`
+	Summary   string `json:"summary"`
+	Detail    string `json:"detail"`
+	Attribute string `json:"attribute"`
+}
+
+// ParseErrorFile parses the contents of an error file as diagnostics; false is returned if the contents aren't a
+// structured error file.
+func ParseErrorFile(by []byte) (diag.Diagnostics, bool) {
+	var ef ErrorFile
+	if err := json.Unmarshal(bytes.TrimPrefix(by, []byte("\ufeff")), &ef); err != nil || ef.Diagnostics == nil {
+		return nil, false
+	}
+
+	diags := diag.Diagnostics{}
+	for _, d := range ef.Diagnostics {
+		p, ok := parseInputsPath(d.Attribute)
+
+		switch {
+		case d.Severity == DiagnosticSeverityWarning && ok:
+			diags.AddAttributeWarning(p, d.Summary, d.Detail)
+		case d.Severity == DiagnosticSeverityWarning:
+			diags.AddWarning(d.Summary, d.Detail)
+		case ok:
+			diags.AddAttributeError(p, d.Summary, d.Detail)
+		default:
+			diags.AddError(d.Summary, d.Detail)
+		}
+	}
+
+	return diags, true
+}
+
+// parseInputsPath parses an attribute path under inputs (e.g. inputs.users[0].name) into a path.
+func parseInputsPath(attribute string) (path.Path, bool) {
+	rel, ok := strings.CutPrefix(attribute, inputsAttribute)
+	if !ok {
+		return path.Empty(), false
+	}
+
+	switch {
+	case len(rel) == 0:
+		return path.Root(inputsAttribute), true
+	case strings.HasPrefix(rel, "."):
+		rel = rel[1:]
+	case !strings.HasPrefix(rel, "["):
+		return path.Empty(), false
+	}
+
+	p, err := tfdynamic.AttributePath(path.Root(inputsAttribute), rel)
+	if err != nil {
+		return path.Empty(), false
+	}
+
+	return p, true
+}
@@ -0,0 +1,98 @@
+package script_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+)
+
+func TestParseErrorFile(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		content  string
+		want     diag.Diagnostics
+		wantOK   bool
+	}{
+		{
+			testName: "text",
+			content:  "something went wrong",
+			want:     nil,
+			wantOK:   false,
+		},
+		{
+			testName: "empty",
+			content:  "",
+			want:     nil,
+			wantOK:   false,
+		},
+		{
+			testName: "json_without_diagnostics",
+			content:  `{"message": "something went wrong"}`,
+			want:     nil,
+			wantOK:   false,
+		},
+		{
+			testName: "error_and_warning",
+			content:  `{"diagnostics": [{"severity": "error", "summary": "Failed.", "detail": "it failed"}, {"severity": "warning", "summary": "Careful.", "detail": "be careful"}]}`,
+			want: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed.", "it failed"),
+				diag.NewWarningDiagnostic("Careful.", "be careful"),
+			},
+			wantOK: true,
+		},
+		{
+			testName: "unknown_severity_is_error",
+			content:  `{"diagnostics": [{"summary": "Failed."}]}`,
+			want: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed.", ""),
+			},
+			wantOK: true,
+		},
+		{
+			testName: "attribute",
+			content:  `{"diagnostics": [{"severity": "error", "summary": "Invalid name.", "attribute": "inputs.users[1].name"}, {"severity": "warning", "summary": "Deprecated.", "attribute": "inputs.legacy"}]}`,
+			want: diag.Diagnostics{
+				diag.NewAttributeErrorDiagnostic(path.Root("inputs").AtName("users").AtListIndex(1).AtName("name"), "Invalid name.", ""),
+				diag.NewAttributeWarningDiagnostic(path.Root("inputs").AtName("legacy"), "Deprecated.", ""),
+			},
+			wantOK: true,
+		},
+		{
+			testName: "attribute_outside_inputs",
+			content:  `{"diagnostics": [{"severity": "error", "summary": "Failed.", "attribute": "output.foo"}]}`,
+			want: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Failed.", ""),
+			},
+			wantOK: true,
+		},
+		{
+			testName: "invalid_attribute",
+			content:  `{"diagnostics": [{"severity": "error", "summary": "Invalid path.", "attribute": "inputs.users.[a]"}, {"severity": "error", "summary": "Invalid root.", "attribute": "inputsfoo"}]}`,
+			want: diag.Diagnostics{
+				diag.NewErrorDiagnostic("Invalid path.", ""),
+				diag.NewErrorDiagnostic("Invalid root.", ""),
+			},
+			wantOK: true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := script.ParseErrorFile([]byte(d.content))
+
+			if ok != d.wantOK {
+				t.Errorf("ParseErrorFile() ok = %v, want %v", ok, d.wantOK)
+			}
+
+			if diff := cmp.Diff(d.want, got); diff != "" {
+				t.Errorf("ParseErrorFile() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,11 +1,23 @@
 package script
 
 import (
+	"cmp"
 	"context"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/terr4m/terraform-provider-shell/internal/shell"
 )
 
+// redactedValue replaces sensitive values in log messages.
+const redactedValue = "***"
+
+// minRedactedValueLength is the minimum length of a sensitive value to be redacted; shorter values such as 1 or yes
+// would redact unrelated text in every log message.
+const minRedactedValueLength = 4
+
 // TFLogLogger provides a logging implementation using tflog.
 type TFLogLogger struct{}
 
@@ -33,3 +45,93 @@ func (l *TFLogLogger) Debug(ctx context.Context, msg string, additionalFields ..
 func (l *TFLogLogger) Trace(ctx context.Context, msg string, additionalFields ...map[string]any) {
 	tflog.Trace(ctx, msg, additionalFields...)
 }
+
+// ProgressLogger provides a logging implementation using tflog which also reports each message to a progress function.
+type ProgressLogger struct {
+	TFLogLogger
+
+	Progress func(msg string)
+}
+
+// Error logs an error message to tflog and reports it as progress.
+func (l *ProgressLogger) Error(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.TFLogLogger.Error(ctx, msg, additionalFields...)
+	l.Progress(msg)
+}
+
+// Warn logs a warning message to tflog and reports it as progress.
+func (l *ProgressLogger) Warn(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.TFLogLogger.Warn(ctx, msg, additionalFields...)
+	l.Progress(msg)
+}
+
+// Info logs an informational message to tflog and reports it as progress.
+func (l *ProgressLogger) Info(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.TFLogLogger.Info(ctx, msg, additionalFields...)
+	l.Progress(msg)
+}
+
+// Debug logs a debug message to tflog and reports it as progress.
+func (l *ProgressLogger) Debug(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.TFLogLogger.Debug(ctx, msg, additionalFields...)
+	l.Progress(msg)
+}
+
+// Trace logs a trace message to tflog and reports it as progress.
+func (l *ProgressLogger) Trace(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.TFLogLogger.Trace(ctx, msg, additionalFields...)
+	l.Progress(msg)
+}
+
+// redactLogger provides a logging implementation which redacts sensitive values before passing messages to a logger.
+type redactLogger struct {
+	logger   shell.Logger
+	replacer *strings.Replacer
+}
+
+// newRedactLogger creates a new redactLogger; the longest values are redacted first so overlapping values are fully
+// redacted and values shorter than minRedactedValueLength are ignored.
+func newRedactLogger(logger shell.Logger, values []string) *redactLogger {
+	values = slices.Clone(values)
+	slices.SortFunc(values, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+
+	oldnew := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		if len(v) < minRedactedValueLength {
+			continue
+		}
+		oldnew = append(oldnew, v, redactedValue)
+	}
+
+	return &redactLogger{
+		logger:   logger,
+		replacer: strings.NewReplacer(oldnew...),
+	}
+}
+
+// Error logs a redacted error message.
+func (l *redactLogger) Error(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.logger.Error(ctx, l.replacer.Replace(msg), additionalFields...)
+}
+
+// Warn logs a redacted warning message.
+func (l *redactLogger) Warn(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.logger.Warn(ctx, l.replacer.Replace(msg), additionalFields...)
+}
+
+// Info logs a redacted informational message.
+func (l *redactLogger) Info(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.logger.Info(ctx, l.replacer.Replace(msg), additionalFields...)
+}
+
+// Debug logs a redacted debug message.
+func (l *redactLogger) Debug(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.logger.Debug(ctx, l.replacer.Replace(msg), additionalFields...)
+}
+
+// Trace logs a redacted trace message.
+func (l *redactLogger) Trace(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	l.logger.Trace(ctx, l.replacer.Replace(msg), additionalFields...)
+}
@@ -3,6 +3,8 @@ package script_test
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/terr4m/terraform-provider-shell/internal/script"
 )
 
@@ -18,3 +20,25 @@ func TestTFLogLogger(t *testing.T) {
 	logger.Debug(ctx, "debug")
 	logger.Trace(ctx, "trace")
 }
+
+func TestProgressLogger(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	var got []string
+	logger := script.ProgressLogger{
+		Progress: func(msg string) {
+			got = append(got, msg)
+		},
+	}
+	logger.Error(ctx, "error")
+	logger.Warn(ctx, "warn")
+	logger.Info(ctx, "info")
+	logger.Debug(ctx, "debug")
+	logger.Trace(ctx, "trace")
+
+	if diff := cmp.Diff([]string{"error", "warn", "info", "debug", "trace"}, got); diff != "" {
+		t.Errorf("Progress mismatch (-want +got):\n%s", diff)
+	}
+}
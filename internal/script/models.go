@@ -3,18 +3,59 @@ package script
 const (
 	LifecycleEnv            string = "TF_SCRIPT_LIFECYCLE"
 	InputsEnv               string = "TF_SCRIPT_INPUTS"
+	InputsFileEnv           string = "TF_SCRIPT_INPUTS_FILE"
+	SensitiveInputsEnv      string = "TF_SCRIPT_SENSITIVE_INPUTS"
+	SensitiveInputsFileEnv  string = "TF_SCRIPT_SENSITIVE_INPUTS_FILE"
+	WriteOnlyInputsEnv      string = "TF_SCRIPT_INPUTS_WO"
+	WriteOnlyInputsFileEnv  string = "TF_SCRIPT_INPUTS_WO_FILE"
 	StateOutputEnv          string = "TF_SCRIPT_STATE_OUTPUT"
+	StateOutputFileEnv      string = "TF_SCRIPT_STATE_OUTPUT_FILE"
 	ScriptOutputFilePathEnv string = "TF_SCRIPT_OUTPUT"
 	ScriptErrorFilePathEnv  string = "TF_SCRIPT_ERROR"
+	ImportIDEnv             string = "TF_SCRIPT_IMPORT_ID"
+	OperationContextEnv     string = "TF_SCRIPT_OPERATION_CONTEXT"
+	PrivateEnv              string = "TF_SCRIPT_PRIVATE"
+	PrivateFileEnv          string = "TF_SCRIPT_PRIVATE_FILE"
 )
 
 // Lifecycle represents a Terraform lifecycle stage.
 type Lifecycle string
 
 const (
-	LifecyclePlan   Lifecycle = "plan"
-	LifecycleCreate Lifecycle = "create"
-	LifecycleRead   Lifecycle = "read"
-	LifecycleUpdate Lifecycle = "update"
-	LifecycleDelete Lifecycle = "delete"
+	LifecyclePlan     Lifecycle = "plan"
+	LifecycleCreate   Lifecycle = "create"
+	LifecycleRead     Lifecycle = "read"
+	LifecycleUpdate   Lifecycle = "update"
+	LifecycleDelete   Lifecycle = "delete"
+	LifecycleImport   Lifecycle = "import"
+	LifecycleOpen     Lifecycle = "open"
+	LifecycleRenew    Lifecycle = "renew"
+	LifecycleClose    Lifecycle = "close"
+	LifecycleFunction Lifecycle = "function"
+	LifecycleInvoke   Lifecycle = "invoke"
+)
+
+// OperationContext represents why a command is being run as part of a Terraform operation.
+type OperationContext string
+
+const (
+	OperationContextReplace OperationContext = "replace"
+	OperationContextDestroy OperationContext = "destroy"
+)
+
+// InputMode represents how inputs and the state output are passed to a command.
+type InputMode string
+
+const (
+	InputModeEnv   InputMode = "env"
+	InputModeFile  InputMode = "file"
+	InputModeStdin InputMode = "stdin"
+)
+
+// OutputSource represents where the JSON output of a command is read from.
+type OutputSource string
+
+const (
+	OutputSourceFile   OutputSource = "file"
+	OutputSourceStdout OutputSource = "stdout"
 )
@@ -0,0 +1,51 @@
+package script
+
+import (
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// RetryPolicy describes how a failed command is retried; the zero value runs a command once.
+type RetryPolicy struct {
+	MaxAttempts        int
+	InitialBackoff     time.Duration
+	MaxBackoff         time.Duration
+	Multiplier         float64
+	Jitter             float64
+	RetryableExitCodes []int
+	ErrorPattern       *regexp.Regexp
+}
+
+// Retryable returns true if a command that failed with the exit code and error text should be retried; if neither
+// retryable exit codes nor an error pattern are set all failures are retryable, otherwise either must match.
+func (p RetryPolicy) Retryable(exitCode int, errorText string) bool {
+	if len(p.RetryableExitCodes) == 0 && p.ErrorPattern == nil {
+		return true
+	}
+
+	if slices.Contains(p.RetryableExitCodes, exitCode) {
+		return true
+	}
+
+	return p.ErrorPattern != nil && p.ErrorPattern.MatchString(errorText)
+}
+
+// Backoff returns the time to wait after the given failed attempt, starting from 1; the backoff grows by the multiplier
+// for each attempt, is randomized by up to the jitter fraction in either direction and is then limited to the max backoff.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := max(p.Multiplier, 1)
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(max(attempt, 1)-1))
+
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+
+	return time.Duration(max(backoff, 0))
+}
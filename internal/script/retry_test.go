@@ -0,0 +1,126 @@
+package script_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+)
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName  string
+		policy    script.RetryPolicy
+		exitCode  int
+		errorText string
+		want      bool
+	}{
+		{
+			testName: "any_failure",
+			policy:   script.RetryPolicy{},
+			exitCode: 1,
+			want:     true,
+		},
+		{
+			testName: "exit_code_match",
+			policy:   script.RetryPolicy{RetryableExitCodes: []int{75, 111}},
+			exitCode: 111,
+			want:     true,
+		},
+		{
+			testName: "exit_code_no_match",
+			policy:   script.RetryPolicy{RetryableExitCodes: []int{75}},
+			exitCode: 1,
+			want:     false,
+		},
+		{
+			testName:  "error_pattern_match",
+			policy:    script.RetryPolicy{ErrorPattern: regexp.MustCompile(`(?i)too many requests`)},
+			exitCode:  1,
+			errorText: "HTTP 429: Too Many Requests",
+			want:      true,
+		},
+		{
+			testName:  "error_pattern_no_match",
+			policy:    script.RetryPolicy{ErrorPattern: regexp.MustCompile(`timeout`)},
+			exitCode:  1,
+			errorText: "not found",
+			want:      false,
+		},
+		{
+			testName:  "exit_code_or_error_pattern",
+			policy:    script.RetryPolicy{RetryableExitCodes: []int{75}, ErrorPattern: regexp.MustCompile(`timeout`)},
+			exitCode:  1,
+			errorText: "connection timeout",
+			want:      true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := d.policy.Retryable(d.exitCode, d.errorText); got != d.want {
+				t.Errorf("Retryable() = %v, want %v", got, d.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		policy   script.RetryPolicy
+		attempt  int
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{
+			testName: "first_attempt",
+			policy:   script.RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempt:  1,
+			wantMin:  time.Second,
+			wantMax:  time.Second,
+		},
+		{
+			testName: "multiplier",
+			policy:   script.RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempt:  3,
+			wantMin:  4 * time.Second,
+			wantMax:  4 * time.Second,
+		},
+		{
+			testName: "max_backoff",
+			policy:   script.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Multiplier: 2},
+			attempt:  5,
+			wantMin:  3 * time.Second,
+			wantMax:  3 * time.Second,
+		},
+		{
+			testName: "jitter",
+			policy:   script.RetryPolicy{InitialBackoff: 10 * time.Second, Multiplier: 1, Jitter: 0.5},
+			attempt:  2,
+			wantMin:  5 * time.Second,
+			wantMax:  15 * time.Second,
+		},
+		{
+			testName: "jitter_max_backoff",
+			policy:   script.RetryPolicy{InitialBackoff: 10 * time.Second, MaxBackoff: 10 * time.Second, Multiplier: 1, Jitter: 0.5},
+			attempt:  2,
+			wantMin:  5 * time.Second,
+			wantMax:  10 * time.Second,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got := d.policy.Backoff(d.attempt)
+			if got < d.wantMin || got > d.wantMax {
+				t.Errorf("Backoff() = %v, want between %v and %v", got, d.wantMin, d.wantMax)
+			}
+		})
+	}
+}
@@ -1,40 +1,77 @@
 package script
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/terr4m/terraform-provider-shell/internal/shell"
 )
 
 // RunOptions contains the options for running a command.
 type RunOptions struct {
-	Interpreter      []string
-	Environment      map[string]string
-	WorkingDirectory string
-	Command          string
-	Lifecycle        Lifecycle
-	Inputs           any
-	StateOutput      any
-	ReadJSON         bool
+	Interpreter            []string
+	Environment            map[string]string
+	SensitiveEnvironment   map[string]string
+	Inherit                shell.InheritEnvironment
+	WorkingDirectory       string
+	Command                string
+	Lifecycle              Lifecycle
+	Inputs                 any
+	SensitiveInputs        any
+	WriteOnlyInputs        any
+	StateOutput            any
+	Private                any
+	ImportID               string
+	OperationContext       OperationContext
+	InputMode              InputMode
+	OutputSource           OutputSource
+	OutputFormat           shell.OutputFormat
+	OutputSchema           string
+	Retry                  RetryPolicy
+	TerminationGracePeriod time.Duration
+	ReadJSON               bool
+	ReadPartialOutput      bool
 }
 
 // RunResult represents the result of running a command.
 type RunResult struct {
-	Meta   ResultMetadata
-	Output any
+	Meta ResultMetadata
+	// Private contains the value of the __private output key, which is nil if the key isn't set.
+	Private any
+	Output  any
+}
+
+// stdinDocument is the JSON document written to the command stdin when using the stdin input mode.
+type stdinDocument struct {
+	Lifecycle       Lifecycle `json:"lifecycle"`
+	Inputs          any       `json:"inputs"`
+	SensitiveInputs any       `json:"sensitive_inputs,omitempty"`
+	WriteOnlyInputs any       `json:"inputs_wo,omitempty"`
+	StateOutput     any       `json:"state_output"`
+	Private         any       `json:"private,omitempty"`
 }
 
 // ResultMetadata represents metadata from running a command.
 type ResultMetadata struct {
-	OutputDriftDetected bool `json:"output_drift_detected"`
+	OutputDriftDetected bool     `json:"output_drift_detected"`
+	RenewAt             string   `json:"renew_at"`
+	SensitivePaths      []string `json:"sensitive_paths"`
+	UnknownPaths        []string `json:"unknown_paths"`
+	RequiresReplace     bool     `json:"requires_replace"`
+	// RequiresReplacePaths contains the input paths set when requires_replace is a list instead of a bool.
+	RequiresReplacePaths []string `json:"-"`
 }
 
 // CommandRunner runs shell scripts and returns parsed results.
@@ -70,82 +107,375 @@ func (r *shellCommandRunner) Run(ctx context.Context, opts RunOptions) (RunResul
 	}
 	defer os.Remove(errorFilePath)
 
-	environment := make(map[string]string, len(opts.Environment)+5)
+	environment := make(map[string]string, len(opts.Environment)+len(opts.SensitiveEnvironment)+8)
 	maps.Copy(environment, opts.Environment)
+	maps.Copy(environment, opts.SensitiveEnvironment)
 
 	environment[LifecycleEnv] = string(opts.Lifecycle)
 	environment[ScriptOutputFilePathEnv] = outFilePath
 	environment[ScriptErrorFilePathEnv] = errorFilePath
 
-	if opts.Inputs != nil {
-		by, err := json.Marshal(opts.Inputs)
+	payloads := []struct {
+		name    string
+		value   any
+		env     string
+		fileEnv string
+	}{
+		{name: "inputs", value: opts.Inputs, env: InputsEnv, fileEnv: InputsFileEnv},
+		{name: "sensitive inputs", value: opts.SensitiveInputs, env: SensitiveInputsEnv, fileEnv: SensitiveInputsFileEnv},
+		{name: "write-only inputs", value: opts.WriteOnlyInputs, env: WriteOnlyInputsEnv, fileEnv: WriteOnlyInputsFileEnv},
+		{name: "state output", value: opts.StateOutput, env: StateOutputEnv, fileEnv: StateOutputFileEnv},
+		{name: "private", value: opts.Private, env: PrivateEnv, fileEnv: PrivateFileEnv},
+	}
+
+	var stdin []byte
+	switch opts.InputMode {
+	case InputModeStdin:
+		by, err := json.Marshal(stdinDocument{
+			Lifecycle:       opts.Lifecycle,
+			Inputs:          opts.Inputs,
+			SensitiveInputs: opts.SensitiveInputs,
+			WriteOnlyInputs: opts.WriteOnlyInputs,
+			StateOutput:     opts.StateOutput,
+			Private:         opts.Private,
+		})
 		if err != nil {
-			diags.AddError("Failed to marshal inputs.", err.Error())
+			diags.AddError("Failed to marshal stdin document.", err.Error())
 			return res, diags
 		}
 
-		environment[InputsEnv] = string(by)
+		stdin = by
+	default:
+		for _, p := range payloads {
+			if p.value == nil {
+				continue
+			}
+
+			by, err := json.Marshal(p.value)
+			if err != nil {
+				diags.AddError(fmt.Sprintf("Failed to marshal %s.", p.name), err.Error())
+				return res, diags
+			}
+
+			if opts.InputMode != InputModeFile {
+				environment[p.env] = string(by)
+				continue
+			}
+
+			filePath, err := shell.GetInputFilePath(strings.ReplaceAll(p.name, " ", "-"))
+			if err != nil {
+				diags.AddError(fmt.Sprintf("Failed to get %s file path.", p.name), err.Error())
+				return res, diags
+			}
+			defer os.Remove(filePath)
+
+			if err := os.WriteFile(filePath, by, 0o600); err != nil {
+				diags.AddError(fmt.Sprintf("Failed to write %s file.", p.name), err.Error())
+				return res, diags
+			}
+
+			environment[p.fileEnv] = filePath
+		}
 	}
 
-	if opts.StateOutput != nil {
-		by, err := json.Marshal(opts.StateOutput)
-		if err != nil {
-			diags.AddError("Failed to marshal state output.", err.Error())
-			return res, diags
+	if opts.ImportID != "" {
+		environment[ImportIDEnv] = opts.ImportID
+	}
+
+	if opts.OperationContext != "" {
+		environment[OperationContextEnv] = string(opts.OperationContext)
+	}
+
+	logProvider := r.logProvider
+	if logProvider != nil {
+		if values := sensitiveValues(opts.SensitiveEnvironment, opts.SensitiveInputs, opts.WriteOnlyInputs); len(values) > 0 {
+			logProvider = &shell.LogProvider{
+				Logger: newRedactLogger(logProvider.Logger, values),
+			}
+		}
+	}
+
+	readStdout := opts.ReadJSON && opts.OutputSource == OutputSourceStdout
+	var stdout bytes.Buffer
+
+	// A failed attempt can have written output, such as the ID of a created object, before a retry which fails without
+	// writing any; the last partial output is kept so it isn't lost.
+	var lastPartial RunResult
+	partialOutput := func() RunResult {
+		if partial := readPartialOutput(ctx, opts, stdout.Bytes(), outFilePath); partial.Output != nil {
+			return partial
 		}
 
-		environment[StateOutputEnv] = string(by)
+		return lastPartial
 	}
 
-	err = shell.RunCommand(ctx, opts.Interpreter, environment, opts.WorkingDirectory, opts.Command, r.logProvider)
-	if err != nil {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			lastPartial = partialOutput()
+
+			// Clear any files written by the previous attempt.
+			for _, p := range []string{outFilePath, errorFilePath} {
+				if err := os.Truncate(p, 0); err != nil {
+					diags.AddError("Failed to reset command files.", err.Error())
+					return res, diags
+				}
+			}
+		}
+
+		tflog.Debug(ctx, "Running command.", map[string]any{"lifecycle": string(opts.Lifecycle), "attempt": attempt, "max_attempts": max(opts.Retry.MaxAttempts, 1)})
+
+		var stdinReader io.Reader
+		if stdin != nil {
+			stdinReader = bytes.NewReader(stdin)
+		}
+
+		var stdoutWriter io.Writer
+		if readStdout {
+			stdout.Reset()
+			stdoutWriter = &stdout
+		}
+
+		err = shell.RunCommand(ctx, opts.Interpreter, environment, opts.Inherit, opts.WorkingDirectory, opts.Command, stdinReader, stdoutWriter, opts.TerminationGracePeriod, logProvider)
+		if err == nil {
+			// A successful command can still write warnings to a structured error file.
+			if by, err := os.ReadFile(errorFilePath); err == nil {
+				if errorDiags, ok := ParseErrorFile(by); ok {
+					diags.Append(errorDiags...)
+				}
+			}
+			break
+		}
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			diags.AddError("Command timed out.", "the command was stopped because it didn't complete before the timeout; it didn't exit with an error, so consider increasing the timeouts value")
+			return partialOutput(), diags
+		}
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			diags.AddError("Command cancelled.", "the command was stopped because the operation was cancelled")
+			return partialOutput(), diags
+		}
+
 		exitError := &exec.ExitError{}
-		if errors.As(err, &exitError) {
-			detail := ""
-			by, err := os.ReadFile(errorFilePath)
-			if err == nil {
-				detail = string(by)
+		if !errors.As(err, &exitError) {
+			diags.AddError("Failed to run command.", err.Error())
+			return partialOutput(), diags
+		}
+
+		detail := ""
+		by, err := os.ReadFile(errorFilePath)
+		if err == nil {
+			detail = string(by)
+		}
+
+		tflog.Warn(ctx, "Command attempt failed.", map[string]any{"lifecycle": string(opts.Lifecycle), "attempt": attempt, "exit_code": exitError.ExitCode()})
+
+		if attempt < opts.Retry.MaxAttempts && opts.Retry.Retryable(exitError.ExitCode(), detail) && waitForRetry(ctx, opts.Retry.Backoff(attempt)) {
+			continue
+		}
+
+		res = partialOutput()
+
+		summary := fmt.Sprintf("Command failed with exit code: %d", exitError.ExitCode())
+		if errorDiags, ok := ParseErrorFile([]byte(detail)); ok {
+			diags.Append(errorDiags...)
+			if !errorDiags.HasError() {
+				diags.AddError(summary, "the command didn't report any error diagnostics")
 			}
-			diags.AddError(fmt.Sprintf("Command failed with exit code: %d", exitError.ExitCode()), detail)
 			return res, diags
 		}
 
-		diags.AddError("Failed to run command.", err.Error())
+		diags.AddError(summary, detail)
 		return res, diags
 	}
 
+	// A successful command can still report errors, in which case any output it wrote is treated as partial output.
+	if diags.HasError() {
+		return partialOutput(), diags
+	}
+
 	if !opts.ReadJSON {
 		return res, diags
 	}
 
-	out, err := shell.ReadJSON(outFilePath)
-	if err != nil {
-		diags.AddError("Failed to read output file.", err.Error())
-		return res, diags
+	var out any
+	if readStdout {
+		out, err = shell.ParseOutput(stdout.Bytes(), opts.OutputFormat)
+		if err != nil {
+			diags.AddError("Failed to read command stdout.", err.Error())
+			return res, diags
+		}
+	} else {
+		out, err = shell.ReadOutput(outFilePath, opts.OutputFormat)
+		if err != nil {
+			diags.AddError("Failed to read output file.", err.Error())
+			return res, diags
+		}
 	}
 
 	res = GetRunCommandResult(out)
 
+	// The plan output isn't validated as it can contain unknown values.
+	if opts.OutputSchema != "" && opts.Lifecycle != LifecyclePlan {
+		sch, err := LoadSchema(opts.OutputSchema)
+		if err != nil {
+			diags.AddError("Invalid output schema.", err.Error())
+			return RunResult{}, diags
+		}
+
+		violations, err := sch.Validate(res.Output)
+		if err != nil {
+			diags.AddError("Failed to validate output.", err.Error())
+			return RunResult{}, diags
+		}
+
+		for _, v := range violations {
+			diags.AddError("Output doesn't match the output schema.", fmt.Sprintf("%s: %s", v.Path("output"), v.Message))
+		}
+
+		if diags.HasError() {
+			return RunResult{}, diags
+		}
+	}
+
 	return res, diags
 }
 
+// readPartialOutput reads the output written by a failed command if the run options allow it; an empty result is
+// returned if the command didn't write any output or it can't be parsed.
+func readPartialOutput(ctx context.Context, opts RunOptions, stdout []byte, outFilePath string) RunResult {
+	if !opts.ReadJSON || !opts.ReadPartialOutput {
+		return RunResult{}
+	}
+
+	by := stdout
+	if opts.OutputSource != OutputSourceStdout {
+		var err error
+		by, err = os.ReadFile(outFilePath)
+		if err != nil {
+			return RunResult{}
+		}
+	}
+
+	if len(bytes.TrimSpace(by)) == 0 {
+		return RunResult{}
+	}
+
+	out, err := shell.ParseOutput(by, opts.OutputFormat)
+	if err != nil {
+		tflog.Warn(ctx, "Failed to read partial output.", map[string]any{"lifecycle": string(opts.Lifecycle), "error": err.Error()})
+		return RunResult{}
+	}
+
+	return GetRunCommandResult(out)
+}
+
 // GetRunCommandResult extracts the RunResult from the output.
 func GetRunCommandResult(o any) RunResult {
-	if om, ok := o.(map[string]any); ok {
-		if m, ok := om["__meta"].(map[string]any); ok {
-			meta := ResultMetadata{}
-			if outputDriftDetected, ok := m["output_drift_detected"].(bool); ok {
-				meta.OutputDriftDetected = outputDriftDetected
+	om, ok := o.(map[string]any)
+	if !ok {
+		return RunResult{
+			Output: o,
+		}
+	}
+
+	res := RunResult{
+		Output: om,
+	}
+
+	if m, ok := om["__meta"].(map[string]any); ok {
+		meta := ResultMetadata{}
+		if outputDriftDetected, ok := m["output_drift_detected"].(bool); ok {
+			meta.OutputDriftDetected = outputDriftDetected
+		}
+		if renewAt, ok := m["renew_at"].(string); ok {
+			meta.RenewAt = renewAt
+		}
+		if sensitivePaths, ok := m["sensitive_paths"].([]any); ok {
+			for _, p := range sensitivePaths {
+				if s, ok := p.(string); ok {
+					meta.SensitivePaths = append(meta.SensitivePaths, s)
+				}
 			}
-			delete(om, "__meta")
-			return RunResult{
-				Meta:   meta,
-				Output: om,
+		}
+		if unknownPaths, ok := m["unknown_paths"].([]any); ok {
+			for _, p := range unknownPaths {
+				if s, ok := p.(string); ok {
+					meta.UnknownPaths = append(meta.UnknownPaths, s)
+				}
+			}
+		}
+		switch requiresReplace := m["requires_replace"].(type) {
+		case bool:
+			meta.RequiresReplace = requiresReplace
+		case []any:
+			for _, p := range requiresReplace {
+				if s, ok := p.(string); ok {
+					meta.RequiresReplacePaths = append(meta.RequiresReplacePaths, s)
+				}
 			}
 		}
+		delete(om, "__meta")
+		res.Meta = meta
+	}
+
+	if private, ok := om["__private"]; ok {
+		delete(om, "__private")
+		res.Private = private
+	}
+
+	return res
+}
+
+// waitForRetry waits for the backoff before the next attempt; false is returned if the context deadline would be
+// reached before the backoff has elapsed or the context is done.
+func waitForRetry(ctx context.Context, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+		tflog.Warn(ctx, "Not retrying command as the timeout would be reached.", map[string]any{"backoff": backoff.String()})
+		return false
+	}
+
+	tflog.Info(ctx, "Retrying command.", map[string]any{"backoff": backoff.String()})
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// sensitiveValues returns the sensitive environment values and the string and number values from the sensitive inputs;
+// numbers are returned in their JSON form. Bool values aren't returned as redacting them would redact every bool.
+func sensitiveValues(sensitiveEnvironment map[string]string, sensitiveInputs ...any) []string {
+	values := make([]string, 0, len(sensitiveEnvironment))
+	for _, v := range sensitiveEnvironment {
+		values = append(values, v)
 	}
 
-	return RunResult{
-		Output: o,
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case string:
+			values = append(values, val)
+		case json.Number:
+			values = append(values, val.String())
+		case map[string]any:
+			for _, e := range val {
+				walk(e)
+			}
+		case []any:
+			for _, e := range val {
+				walk(e)
+			}
+		}
+	}
+	for _, inputs := range sensitiveInputs {
+		walk(inputs)
 	}
+
+	return values
 }
@@ -3,9 +3,15 @@ package script_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"path"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -134,6 +140,97 @@ func TestShellCommandRunner_Run(t *testing.T) {
 			wantError:      false,
 			wantErrorCount: 0,
 		},
+		{
+			testName: "read_json_true_with_renew_at",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"key":"value","__meta":{"renew_at":"2025-01-02T03:04:05Z"}}`),
+				Lifecycle:   script.LifecycleOpen,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:   script.ResultMetadata{RenewAt: "2025-01-02T03:04:05Z"},
+				Output: map[string]any{"key": "value"},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
+		{
+			testName: "read_json_true_with_sensitive_paths",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"key":"value","secret":"s3cr3t","__meta":{"sensitive_paths":["secret","nested.key"]}}`),
+				Lifecycle:   script.LifecycleRead,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:   script.ResultMetadata{SensitivePaths: []string{"secret", "nested.key"}},
+				Output: map[string]any{"key": "value", "secret": "s3cr3t"},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
+		{
+			testName: "read_json_true_with_unknown_paths",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"id":"","endpoints":[],"__meta":{"unknown_paths":["id","endpoints[0].ip"]}}`),
+				Lifecycle:   script.LifecyclePlan,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:   script.ResultMetadata{UnknownPaths: []string{"id", "endpoints[0].ip"}},
+				Output: map[string]any{"id": "", "endpoints": []any{}},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
+		{
+			testName: "read_json_true_with_requires_replace",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"id":"a","__meta":{"requires_replace":true}}`),
+				Lifecycle:   script.LifecyclePlan,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:   script.ResultMetadata{RequiresReplace: true},
+				Output: map[string]any{"id": "a"},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
+		{
+			testName: "read_json_true_with_requires_replace_paths",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"id":"a","__meta":{"requires_replace":["region","disk.type"]}}`),
+				Lifecycle:   script.LifecyclePlan,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:   script.ResultMetadata{RequiresReplacePaths: []string{"region", "disk.type"}},
+				Output: map[string]any{"id": "a"},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
+		{
+			testName: "read_json_true_with_private",
+			opts: script.RunOptions{
+				Interpreter: interpreter,
+				Command:     testWriteOutputCommand(`{"id":"a","__meta":{"sensitive_paths":["id"]},"__private":{"etag":"abc"}}`),
+				Lifecycle:   script.LifecycleCreate,
+				ReadJSON:    true,
+			},
+			wantResult: script.RunResult{
+				Meta:    script.ResultMetadata{SensitivePaths: []string{"id"}},
+				Private: map[string]any{"etag": "abc"},
+				Output:  map[string]any{"id": "a"},
+			},
+			wantError:      false,
+			wantErrorCount: 0,
+		},
 		{
 			testName: "with_inputs",
 			opts: script.RunOptions{
@@ -249,6 +346,12 @@ func TestShellCommandRunner_Run_LifecycleEnv(t *testing.T) {
 		{testName: "read", lifecycle: script.LifecycleRead, want: "read"},
 		{testName: "update", lifecycle: script.LifecycleUpdate, want: "update"},
 		{testName: "delete", lifecycle: script.LifecycleDelete, want: "delete"},
+		{testName: "import", lifecycle: script.LifecycleImport, want: "import"},
+		{testName: "open", lifecycle: script.LifecycleOpen, want: "open"},
+		{testName: "renew", lifecycle: script.LifecycleRenew, want: "renew"},
+		{testName: "close", lifecycle: script.LifecycleClose, want: "close"},
+		{testName: "function", lifecycle: script.LifecycleFunction, want: "function"},
+		{testName: "invoke", lifecycle: script.LifecycleInvoke, want: "invoke"},
 	} {
 		t.Run(d.testName, func(t *testing.T) {
 			t.Parallel()
@@ -300,7 +403,7 @@ func TestShellCommandRunner_Run_InputsEnv(t *testing.T) {
 	ctx := t.Context()
 	runner := script.NewCommandRunner(nil)
 
-	inputs := map[string]any{"name": "test", "count": float64(42)}
+	inputs := map[string]any{"name": "test", "count": json.Number("9007199254740993")}
 
 	res, diags := runner.Run(ctx, script.RunOptions{
 		Interpreter: interpreter,
@@ -361,6 +464,126 @@ func TestShellCommandRunner_Run_StateOutputEnv(t *testing.T) {
 	}
 }
 
+func TestShellCommandRunner_Run_PrivateEnv(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	var cmd string
+	if runtime.GOOS == "windows" {
+		cmd = `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, ('{"private": ' + $env:TF_SCRIPT_PRIVATE + '}'))`
+	} else {
+		cmd = `printf '{"private": %s}' "${TF_SCRIPT_PRIVATE}" > "${TF_SCRIPT_OUTPUT}"`
+	}
+
+	ctx := t.Context()
+	runner := script.NewCommandRunner(nil)
+
+	private := map[string]any{"etag": "abc"}
+
+	res, diags := runner.Run(ctx, script.RunOptions{
+		Interpreter: interpreter,
+		Command:     cmd,
+		Lifecycle:   script.LifecycleRead,
+		Private:     private,
+		ReadJSON:    true,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags.Errors())
+	}
+
+	if diff := cmp.Diff(map[string]any{"private": private}, res.Output); diff != "" {
+		t.Errorf("private mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestShellCommandRunner_Run_ImportIDEnv(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	var cmd string
+	if runtime.GOOS == "windows" {
+		cmd = `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, ('"' + $env:TF_SCRIPT_IMPORT_ID + '"'))`
+	} else {
+		cmd = `printf '"%s"' "${TF_SCRIPT_IMPORT_ID}" > "${TF_SCRIPT_OUTPUT}"`
+	}
+
+	ctx := t.Context()
+	runner := script.NewCommandRunner(nil)
+
+	res, diags := runner.Run(ctx, script.RunOptions{
+		Interpreter: interpreter,
+		Command:     cmd,
+		Lifecycle:   script.LifecycleImport,
+		ImportID:    "my-id",
+		ReadJSON:    true,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags.Errors())
+	}
+
+	if res.Output != "my-id" {
+		t.Errorf("expected import ID %q, got %v", "my-id", res.Output)
+	}
+}
+
+func TestShellCommandRunner_Run_OperationContextEnv(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	var cmd string
+	if runtime.GOOS == "windows" {
+		cmd = `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, ('"' + $env:TF_SCRIPT_OPERATION_CONTEXT + '"'))`
+	} else {
+		cmd = `printf '"%s"' "${TF_SCRIPT_OPERATION_CONTEXT:-}" > "${TF_SCRIPT_OUTPUT}"`
+	}
+
+	ctx := t.Context()
+	runner := script.NewCommandRunner(nil)
+
+	for _, d := range []struct {
+		testName         string
+		operationContext script.OperationContext
+		expected         string
+	}{
+		{
+			testName:         "replace",
+			operationContext: script.OperationContextReplace,
+			expected:         "replace",
+		},
+		{
+			testName:         "destroy",
+			operationContext: script.OperationContextDestroy,
+			expected:         "destroy",
+		},
+		{
+			testName: "unset",
+			expected: "",
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := runner.Run(ctx, script.RunOptions{
+				Interpreter:      interpreter,
+				Command:          cmd,
+				Lifecycle:        script.LifecycleDelete,
+				OperationContext: d.operationContext,
+				ReadJSON:         true,
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if res.Output != d.expected {
+				t.Errorf("expected operation context %q, got %v", d.expected, res.Output)
+			}
+		})
+	}
+}
+
 func TestShellCommandRunner_Run_EnvironmentMerge(t *testing.T) {
 	t.Parallel()
 
@@ -608,3 +831,693 @@ func TestShellCommandRunner_Run_WithLogProvider(t *testing.T) {
 		t.Errorf("expected INFO log entry with 'hello from script', got: %v", entries)
 	}
 }
+
+func TestShellCommandRunner_Run_Inherit(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Test is not valid on Windows")
+	}
+
+	home, ok := os.LookupEnv("HOME")
+	if !ok || home == "" {
+		t.Skip("HOME is not set")
+	}
+
+	for _, d := range []struct {
+		testName string
+		inherit  shell.InheritEnvironment
+		want     string
+	}{
+		{
+			testName: "all",
+			inherit:  shell.InheritEnvironment{Mode: shell.EnvironmentModeAll},
+			want:     home,
+		},
+		{
+			testName: "none",
+			inherit:  shell.InheritEnvironment{Mode: shell.EnvironmentModeNone},
+			want:     "",
+		},
+		{
+			testName: "allowlist_match",
+			inherit:  shell.InheritEnvironment{Mode: shell.EnvironmentModeAllowlist, Passthrough: []string{"HO*"}},
+			want:     home,
+		},
+		{
+			testName: "allowlist_no_match",
+			inherit:  shell.InheritEnvironment{Mode: shell.EnvironmentModeAllowlist, Passthrough: []string{"PATH"}},
+			want:     "",
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			runner := script.NewCommandRunner(nil)
+
+			res, diags := runner.Run(ctx, script.RunOptions{
+				Interpreter: testInterpreter(),
+				Inherit:     d.inherit,
+				Command:     `printf '"%s"' "${HOME:-}" > "${TF_SCRIPT_OUTPUT}"`,
+				Lifecycle:   script.LifecycleRead,
+				ReadJSON:    true,
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if res.Output != d.want {
+				t.Errorf("expected HOME %q, got %v", d.want, res.Output)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+	logger := &mockLogger{}
+	runner := script.NewCommandRunner(&shell.LogProvider{Logger: logger})
+
+	var cmd string
+	if runtime.GOOS == "windows" {
+		cmd = `Write-Output "[INFO] key=$env:MY_SECRET token=my-token pin=482913 attempts=1 zone=ab"; [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, $env:TF_SCRIPT_SENSITIVE_INPUTS)`
+	} else {
+		cmd = `echo "[INFO] key=${MY_SECRET} token=my-token pin=482913 attempts=1 zone=ab"; printf '%s' "${TF_SCRIPT_SENSITIVE_INPUTS}" > "${TF_SCRIPT_OUTPUT}"`
+	}
+
+	ctx := t.Context()
+	res, diags := runner.Run(ctx, script.RunOptions{
+		Interpreter:          interpreter,
+		SensitiveEnvironment: map[string]string{"MY_SECRET": "my-secret"},
+		Command:              cmd,
+		Lifecycle:            script.LifecycleRead,
+		SensitiveInputs:      map[string]any{"token": "my-token", "pin": json.Number("482913"), "nested": []any{"my-token-2", true, json.Number("1"), "ab"}},
+		ReadJSON:             true,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags.Errors())
+	}
+
+	wantOutput := map[string]any{"token": "my-token", "pin": json.Number("482913"), "nested": []any{"my-token-2", true, json.Number("1"), "ab"}}
+	if diff := cmp.Diff(wantOutput, res.Output); diff != "" {
+		t.Errorf("Run() output mismatch (-want +got):\n%s", diff)
+	}
+
+	entries := logger.getEntries()
+	// Values shorter than the minimum length aren't redacted, so unrelated numbers and text survive.
+	wantEntries := []string{"key=*** token=*** pin=*** attempts=1 zone=ab"}
+	gotEntries := make([]string, 0, len(entries))
+	for _, e := range entries {
+		gotEntries = append(gotEntries, e.msg)
+	}
+	if diff := cmp.Diff(wantEntries, gotEntries); diff != "" {
+		t.Errorf("Run() log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestShellCommandRunner_Run_WriteOnlyInputs(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+	logger := &mockLogger{}
+	runner := script.NewCommandRunner(&shell.LogProvider{Logger: logger})
+
+	var cmd string
+	if runtime.GOOS == "windows" {
+		cmd = `Write-Output "[INFO] password=my-password"; [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, $env:TF_SCRIPT_INPUTS_WO)`
+	} else {
+		cmd = `echo "[INFO] password=my-password"; printf '%s' "${TF_SCRIPT_INPUTS_WO}" > "${TF_SCRIPT_OUTPUT}"`
+	}
+
+	ctx := t.Context()
+	res, diags := runner.Run(ctx, script.RunOptions{
+		Interpreter:     interpreter,
+		Command:         cmd,
+		Lifecycle:       script.LifecycleCreate,
+		WriteOnlyInputs: map[string]any{"password": "my-password"},
+		ReadJSON:        true,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags.Errors())
+	}
+
+	if diff := cmp.Diff(map[string]any{"password": "my-password"}, res.Output); diff != "" {
+		t.Errorf("Run() output mismatch (-want +got):\n%s", diff)
+	}
+
+	entries := logger.getEntries()
+	if len(entries) != 1 || entries[0].msg != "password=***" {
+		t.Errorf("expected redacted log entry, got: %v", entries)
+	}
+}
+
+func TestShellCommandRunner_Run_Retry(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	for _, d := range []struct {
+		testName     string
+		retry        script.RetryPolicy
+		timeout      time.Duration
+		wantAttempts int
+		wantError    bool
+	}{
+		{
+			testName:     "no_retry",
+			retry:        script.RetryPolicy{},
+			wantAttempts: 1,
+			wantError:    true,
+		},
+		{
+			testName:     "retry_until_success",
+			retry:        script.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond},
+			wantAttempts: 3,
+			wantError:    false,
+		},
+		{
+			testName:     "max_attempts",
+			retry:        script.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			wantAttempts: 2,
+			wantError:    true,
+		},
+		{
+			testName:     "retryable_exit_code",
+			retry:        script.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, RetryableExitCodes: []int{75}},
+			wantAttempts: 3,
+			wantError:    false,
+		},
+		{
+			testName:     "not_retryable_exit_code",
+			retry:        script.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, RetryableExitCodes: []int{1}},
+			wantAttempts: 1,
+			wantError:    true,
+		},
+		{
+			testName:     "retryable_error_pattern",
+			retry:        script.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, ErrorPattern: regexp.MustCompile(`temporar`)},
+			wantAttempts: 3,
+			wantError:    false,
+		},
+		{
+			testName:     "timeout_deadline",
+			retry:        script.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute},
+			timeout:      time.Minute,
+			wantAttempts: 1,
+			wantError:    true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			counter := path.Join(t.TempDir(), "attempts")
+
+			var cmd string
+			if runtime.GOOS == "windows" {
+				cmd = fmt.Sprintf(`$n = 1; if (Test-Path '%[1]s') { $n = [int](Get-Content '%[1]s') + 1 }; Set-Content -Path '%[1]s' -Value $n; if ($n -lt 3) { [IO.File]::WriteAllText($env:TF_SCRIPT_ERROR, 'temporary failure'); exit 75 }; [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, '{}')`, counter)
+			} else {
+				cmd = fmt.Sprintf(`n=$(( $(cat '%[1]s' 2>/dev/null || echo 0) + 1 )); echo "${n}" > '%[1]s'; if [[ "${n}" -lt 3 ]]; then printf 'temporary failure' > "${TF_SCRIPT_ERROR}"; exit 75; fi; printf '{}' > "${TF_SCRIPT_OUTPUT}"`, counter)
+			}
+
+			ctx := t.Context()
+			if d.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d.timeout)
+				defer cancel()
+			}
+
+			runner := script.NewCommandRunner(nil)
+			_, diags := runner.Run(ctx, script.RunOptions{
+				Interpreter: interpreter,
+				Command:     cmd,
+				Lifecycle:   script.LifecycleCreate,
+				Retry:       d.retry,
+				ReadJSON:    true,
+			})
+
+			if diags.HasError() != d.wantError {
+				t.Errorf("expected error=%v, got: %v", d.wantError, diags.Errors())
+			}
+
+			by, err := os.ReadFile(counter)
+			if err != nil {
+				t.Fatalf("failed to read attempts: %v", err)
+			}
+
+			got, err := strconv.Atoi(strings.TrimSpace(string(by)))
+			if err != nil {
+				t.Fatalf("failed to parse attempts: %v", err)
+			}
+
+			if got != d.wantAttempts {
+				t.Errorf("expected %d attempts, got: %d", d.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_Timeout(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	cmd := `sleep 10`
+	if runtime.GOOS == "windows" {
+		cmd = `Start-Sleep -Seconds 10`
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	runner := script.NewCommandRunner(nil)
+	_, diags := runner.Run(ctx, script.RunOptions{
+		Interpreter:            interpreter,
+		Command:                cmd,
+		Lifecycle:              script.LifecycleCreate,
+		Retry:                  script.RetryPolicy{MaxAttempts: 3},
+		TerminationGracePeriod: time.Second,
+	})
+
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("expected 1 error, got: %v", diags.Errors())
+	}
+
+	if summary := diags.Errors()[0].Summary(); summary != "Command timed out." {
+		t.Errorf("unexpected error summary: %s", summary)
+	}
+}
+
+func TestShellCommandRunner_Run_ErrorFileDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	for _, d := range []struct {
+		testName     string
+		errorFile    string
+		exitCode     int
+		wantSummary  []string
+		wantErrors   int
+		wantWarnings int
+	}{
+		{
+			testName:     "warning_on_success",
+			errorFile:    `{"diagnostics":[{"severity":"warning","summary":"Deprecated."}]}`,
+			exitCode:     0,
+			wantSummary:  []string{"Deprecated."},
+			wantErrors:   0,
+			wantWarnings: 1,
+		},
+		{
+			testName:     "errors_on_failure",
+			errorFile:    `{"diagnostics":[{"severity":"error","summary":"First."},{"severity":"error","summary":"Second.","attribute":"inputs.name"}]}`,
+			exitCode:     1,
+			wantSummary:  []string{"First.", "Second."},
+			wantErrors:   2,
+			wantWarnings: 0,
+		},
+		{
+			testName:     "warning_on_failure",
+			errorFile:    `{"diagnostics":[{"severity":"warning","summary":"Careful."}]}`,
+			exitCode:     1,
+			wantSummary:  []string{"Careful.", "Command failed with exit code: 1"},
+			wantErrors:   1,
+			wantWarnings: 1,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			var cmd string
+			if runtime.GOOS == "windows" {
+				cmd = fmt.Sprintf(`[IO.File]::WriteAllText($env:TF_SCRIPT_ERROR, '%s'); [IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, '{}'); exit %d`, d.errorFile, d.exitCode)
+			} else {
+				cmd = fmt.Sprintf(`printf '%%s' '%s' > "${TF_SCRIPT_ERROR}"; printf '{}' > "${TF_SCRIPT_OUTPUT}"; exit %d`, d.errorFile, d.exitCode)
+			}
+
+			runner := script.NewCommandRunner(nil)
+			_, diags := runner.Run(t.Context(), script.RunOptions{
+				Interpreter: interpreter,
+				Command:     cmd,
+				Lifecycle:   script.LifecycleCreate,
+				ReadJSON:    true,
+			})
+
+			if diags.ErrorsCount() != d.wantErrors {
+				t.Errorf("expected %d errors, got: %v", d.wantErrors, diags.Errors())
+			}
+
+			if diags.WarningsCount() != d.wantWarnings {
+				t.Errorf("expected %d warnings, got: %v", d.wantWarnings, diags.Warnings())
+			}
+
+			summaries := make([]string, 0, len(diags))
+			for _, diag := range diags {
+				summaries = append(summaries, diag.Summary())
+			}
+
+			if diff := cmp.Diff(d.wantSummary, summaries); diff != "" {
+				t.Errorf("Run() diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_InputMode(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	inputs := map[string]any{"name": "test"}
+	stateOutput := map[string]any{"id": "abc"}
+
+	for _, d := range []struct {
+		testName   string
+		inputMode  script.InputMode
+		command    string
+		winCommand string
+		want       any
+	}{
+		{
+			testName:   "env",
+			inputMode:  script.InputModeEnv,
+			command:    `printf '{"inputs": %s, "file": "%s"}' "${TF_SCRIPT_INPUTS}" "${TF_SCRIPT_INPUTS_FILE:-}" > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, ('{"inputs": ' + $env:TF_SCRIPT_INPUTS + ', "file": "' + $env:TF_SCRIPT_INPUTS_FILE + '"}'))`,
+			want:       map[string]any{"inputs": inputs, "file": ""},
+		},
+		{
+			testName:   "file",
+			inputMode:  script.InputModeFile,
+			command:    `printf '{"inputs": %s, "state_output": %s, "env": "%s"}' "$(cat "${TF_SCRIPT_INPUTS_FILE}")" "$(cat "${TF_SCRIPT_STATE_OUTPUT_FILE}")" "${TF_SCRIPT_INPUTS:-}" > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, ('{"inputs": ' + [IO.File]::ReadAllText($env:TF_SCRIPT_INPUTS_FILE) + ', "state_output": ' + [IO.File]::ReadAllText($env:TF_SCRIPT_STATE_OUTPUT_FILE) + ', "env": "' + $env:TF_SCRIPT_INPUTS + '"}'))`,
+			want:       map[string]any{"inputs": inputs, "state_output": stateOutput, "env": ""},
+		},
+		{
+			testName:   "stdin",
+			inputMode:  script.InputModeStdin,
+			command:    `cat > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `[IO.File]::WriteAllText($env:TF_SCRIPT_OUTPUT, [Console]::In.ReadToEnd())`,
+			want:       map[string]any{"lifecycle": "update", "inputs": inputs, "state_output": stateOutput},
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			command := d.command
+			if runtime.GOOS == "windows" {
+				command = d.winCommand
+			}
+
+			runner := script.NewCommandRunner(nil)
+			res, diags := runner.Run(t.Context(), script.RunOptions{
+				Interpreter: interpreter,
+				Command:     command,
+				Lifecycle:   script.LifecycleUpdate,
+				Inputs:      inputs,
+				StateOutput: stateOutput,
+				InputMode:   d.inputMode,
+				ReadJSON:    true,
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if diff := cmp.Diff(d.want, res.Output); diff != "" {
+				t.Errorf("Run() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_OutputSource(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	for _, d := range []struct {
+		testName     string
+		outputSource script.OutputSource
+		command      string
+		winCommand   string
+		want         any
+		wantErr      bool
+	}{
+		{
+			testName:     "file",
+			outputSource: script.OutputSourceFile,
+			command:      `echo '{"source": "stdout"}'; printf '{"source": "file"}' > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand:   `Write-Output '{"source": "stdout"}'; '{"source": "file"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`,
+			want:         map[string]any{"source": "file"},
+		},
+		{
+			testName:     "stdout",
+			outputSource: script.OutputSourceStdout,
+			command:      `echo '{"source": "stdout", "__meta": {"renew_at": "now"}}'; printf '{"source": "file"}' > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand:   `Write-Output '{"source": "stdout", "__meta": {"renew_at": "now"}}'; '{"source": "file"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`,
+			want:         map[string]any{"source": "stdout"},
+		},
+		{
+			testName:     "stdout_invalid",
+			outputSource: script.OutputSourceStdout,
+			command:      `echo 'not json'`,
+			winCommand:   `Write-Output 'not json'`,
+			wantErr:      true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			command := d.command
+			if runtime.GOOS == "windows" {
+				command = d.winCommand
+			}
+
+			runner := script.NewCommandRunner(nil)
+			res, diags := runner.Run(t.Context(), script.RunOptions{
+				Interpreter:  interpreter,
+				Command:      command,
+				Lifecycle:    script.LifecycleRead,
+				OutputSource: d.outputSource,
+				ReadJSON:     true,
+			})
+			if diags.HasError() != d.wantErr {
+				t.Fatalf("unexpected error state: %v", diags.Errors())
+			}
+
+			if diff := cmp.Diff(d.want, res.Output); diff != "" {
+				t.Errorf("Run() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_OutputSchema(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	sch := `{"type": "object", "properties": {"id": {"type": "string"}, "port": {"type": "integer"}}}`
+
+	for _, d := range []struct {
+		testName   string
+		lifecycle  script.Lifecycle
+		command    string
+		winCommand string
+		want       any
+		wantErrs   int
+	}{
+		{
+			testName:   "valid",
+			lifecycle:  script.LifecycleRead,
+			command:    `printf '{"id": "a", "port": 80}' > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `'{"id": "a", "port": 80}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`,
+			want:       map[string]any{"id": "a", "port": json.Number("80")},
+			wantErrs:   0,
+		},
+		{
+			testName:   "invalid",
+			lifecycle:  script.LifecycleRead,
+			command:    `printf '{"id": 1, "port": "80"}' > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `'{"id": 1, "port": "80"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`,
+			want:       nil,
+			wantErrs:   2,
+		},
+		{
+			testName:   "plan_not_validated",
+			lifecycle:  script.LifecyclePlan,
+			command:    `printf '{"id": "a", "port": "???"}' > "${TF_SCRIPT_OUTPUT}"`,
+			winCommand: `'{"id": "a", "port": "???"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8`,
+			want:       map[string]any{"id": "a", "port": "???"},
+			wantErrs:   0,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			command := d.command
+			if runtime.GOOS == "windows" {
+				command = d.winCommand
+			}
+
+			runner := script.NewCommandRunner(nil)
+			res, diags := runner.Run(t.Context(), script.RunOptions{
+				Interpreter:  interpreter,
+				Command:      command,
+				Lifecycle:    d.lifecycle,
+				OutputSchema: sch,
+				ReadJSON:     true,
+			})
+			if diags.ErrorsCount() != d.wantErrs {
+				t.Fatalf("expected %d errors, got %v", d.wantErrs, diags.Errors())
+			}
+
+			if diff := cmp.Diff(d.want, res.Output); diff != "" {
+				t.Errorf("Run() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShellCommandRunner_Run_PartialOutput(t *testing.T) {
+	t.Parallel()
+
+	interpreter := testInterpreter()
+
+	for _, d := range []struct {
+		testName          string
+		readPartialOutput bool
+		outputSource      script.OutputSource
+		retry             script.RetryPolicy
+		timeout           time.Duration
+		cancelAfter       time.Duration
+		command           string
+		winCommand        string
+		want              script.RunResult
+	}{
+		{
+			testName:          "file",
+			readPartialOutput: true,
+			command:           `printf '{"id": "a", "__private": {"etag": "1"}}' > "${TF_SCRIPT_OUTPUT}"; exit 1`,
+			winCommand:        `'{"id": "a", "__private": {"etag": "1"}}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1`,
+			want:              script.RunResult{Private: map[string]any{"etag": "1"}, Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "stdout",
+			readPartialOutput: true,
+			outputSource:      script.OutputSourceStdout,
+			command:           `echo '{"id": "a"}'; exit 1`,
+			winCommand:        `Write-Output '{"id": "a"}'; exit 1`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "error_diagnostics_on_success",
+			readPartialOutput: true,
+			command:           `printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; printf '{"diagnostics":[{"severity":"error","summary":"Failed."}]}' > "${TF_SCRIPT_ERROR}"`,
+			winCommand:        `'{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; '{"diagnostics":[{"severity":"error","summary":"Failed."}]}' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "error_diagnostics_on_success_stdout",
+			readPartialOutput: true,
+			outputSource:      script.OutputSourceStdout,
+			command:           `echo '{"id": "a"}'; printf '{"diagnostics":[{"severity":"error","summary":"Failed."}]}' > "${TF_SCRIPT_ERROR}"`,
+			winCommand:        `Write-Output '{"id": "a"}'; '{"diagnostics":[{"severity":"error","summary":"Failed."}]}' | Out-File -FilePath $env:TF_SCRIPT_ERROR -Encoding utf8`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "retry_without_output",
+			readPartialOutput: true,
+			retry:             script.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			command:           `if [ -e "${ATTEMPT_FILE}" ]; then exit 1; fi; touch "${ATTEMPT_FILE}"; printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; exit 1`,
+			winCommand:        `if (Test-Path $env:ATTEMPT_FILE) { exit 1 }; New-Item -Path $env:ATTEMPT_FILE | Out-Null; '{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "retry_with_output",
+			readPartialOutput: true,
+			retry:             script.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			command:           `if [ -e "${ATTEMPT_FILE}" ]; then printf '{"id": "b"}' > "${TF_SCRIPT_OUTPUT}"; exit 1; fi; touch "${ATTEMPT_FILE}"; printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; exit 1`,
+			winCommand:        `if (Test-Path $env:ATTEMPT_FILE) { '{"id": "b"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1 }; New-Item -Path $env:ATTEMPT_FILE | Out-Null; '{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1`,
+			want:              script.RunResult{Output: map[string]any{"id": "b"}},
+		},
+		{
+			testName:          "timeout",
+			readPartialOutput: true,
+			timeout:           500 * time.Millisecond,
+			command:           `printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; sleep 30`,
+			winCommand:        `'{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; Start-Sleep -Seconds 30`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "cancelled",
+			readPartialOutput: true,
+			cancelAfter:       500 * time.Millisecond,
+			command:           `printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; sleep 30`,
+			winCommand:        `'{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; Start-Sleep -Seconds 30`,
+			want:              script.RunResult{Output: map[string]any{"id": "a"}},
+		},
+		{
+			testName:          "disabled",
+			readPartialOutput: false,
+			command:           `printf '{"id": "a"}' > "${TF_SCRIPT_OUTPUT}"; exit 1`,
+			winCommand:        `'{"id": "a"}' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1`,
+		},
+		{
+			testName:          "no_output",
+			readPartialOutput: true,
+			command:           `exit 1`,
+			winCommand:        `exit 1`,
+		},
+		{
+			testName:          "invalid_output",
+			readPartialOutput: true,
+			command:           `printf '{"id": ' > "${TF_SCRIPT_OUTPUT}"; exit 1`,
+			winCommand:        `'{"id": ' | Out-File -FilePath $env:TF_SCRIPT_OUTPUT -Encoding utf8; exit 1`,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			command := d.command
+			if runtime.GOOS == "windows" {
+				command = d.winCommand
+			}
+
+			ctx := t.Context()
+			if d.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d.timeout)
+				defer cancel()
+			}
+
+			if d.cancelAfter > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				defer cancel()
+				time.AfterFunc(d.cancelAfter, cancel)
+			}
+
+			runner := script.NewCommandRunner(nil)
+			res, diags := runner.Run(ctx, script.RunOptions{
+				Interpreter:       interpreter,
+				Environment:       map[string]string{"ATTEMPT_FILE": path.Join(t.TempDir(), "attempt")},
+				Command:           command,
+				Lifecycle:         script.LifecycleCreate,
+				OutputSource:      d.outputSource,
+				Retry:             d.retry,
+				ReadJSON:          true,
+				ReadPartialOutput: d.readPartialOutput,
+			})
+			if !diags.HasError() {
+				t.Fatal("expected error, got none")
+			}
+
+			if diff := cmp.Diff(d.want, res); diff != "" {
+				t.Errorf("Run() result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package script
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/terr4m/terraform-provider-shell/internal/tfdynamic"
+)
+
+// inlineSchemaURL is the URL used to identify an inline schema document.
+const inlineSchemaURL = "inline-schema.json"
+
+// schemaPrinter is used to format schema violation messages.
+var schemaPrinter = message.NewPrinter(language.English)
+
+// Schema is a compiled JSON Schema used to validate inputs and output.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+// SchemaViolation describes a value which doesn't match a schema.
+type SchemaViolation struct {
+	// Location is the path of the value which doesn't match the schema relative to the validated value (e.g.
+	// ports[1]); it is empty if the validated value itself doesn't match.
+	Location string
+	Message  string
+}
+
+// LoadSchema compiles a JSON Schema; the schema is either an inline JSON object or the path to a file containing one.
+func LoadSchema(s string) (*Schema, error) {
+	c := jsonschema.NewCompiler()
+
+	loc := strings.TrimSpace(s)
+	if strings.HasPrefix(loc, "{") {
+		doc, err := jsonschema.UnmarshalJSON(strings.NewReader(loc))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema: %w", err)
+		}
+
+		if err := c.AddResource(inlineSchemaURL, doc); err != nil {
+			return nil, err
+		}
+		loc = inlineSchemaURL
+	}
+
+	sch, err := c.Compile(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{schema: sch}, nil
+}
+
+// Validate validates the value against the schema and returns a violation for each value which doesn't match.
+func (s *Schema) Validate(v any) ([]SchemaViolation, error) {
+	err := s.schema.Validate(v)
+	if err == nil {
+		return nil, nil
+	}
+
+	validationErr := &jsonschema.ValidationError{}
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+
+	var violations []SchemaViolation
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			violations = append(violations, SchemaViolation{
+				Location: violationLocation(v, e.InstanceLocation),
+				Message:  e.ErrorKind.LocalizedString(schemaPrinter),
+			})
+			return
+		}
+
+		for _, c := range e.Causes {
+			walk(c)
+		}
+	}
+	walk(validationErr)
+
+	// Properties are validated in map order, so sort the violations to report them consistently.
+	slices.SortStableFunc(violations, func(a, b SchemaViolation) int {
+		return cmp.Or(strings.Compare(a.Location, b.Location), strings.Compare(a.Message, b.Message))
+	})
+
+	return violations, nil
+}
+
+// AttributePath returns the path of the violation under the root attribute.
+func (v SchemaViolation) AttributePath(root string) path.Path {
+	p, err := tfdynamic.AttributePath(path.Root(root), v.Location)
+	if err != nil {
+		return path.Root(root)
+	}
+
+	return p
+}
+
+// Path returns the path of the violation under the root attribute (e.g. output.ports[1]).
+func (v SchemaViolation) Path(root string) string {
+	switch {
+	case len(v.Location) == 0:
+		return root
+	case strings.HasPrefix(v.Location, "["):
+		return root + v.Location
+	default:
+		return root + "." + v.Location
+	}
+}
+
+// violationLocation converts the JSON pointer tokens of a value into a path of object keys and list indexes.
+func violationLocation(v any, tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		switch tv := v.(type) {
+		case []any:
+			sb.WriteString("[" + token + "]")
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(tv) {
+				v = tv[i]
+				continue
+			}
+			v = nil
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(token)
+			m, _ := v.(map[string]any)
+			v = m[token]
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,159 @@
+package script_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/terr4m/terraform-provider-shell/internal/script"
+)
+
+func TestLoadSchema(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		schema   string
+		wantErr  bool
+	}{
+		{
+			testName: "inline",
+			schema:   `{"type": "object"}`,
+			wantErr:  false,
+		},
+		{
+			testName: "inline_whitespace",
+			schema:   "\n  {\"type\": \"object\"}\n",
+			wantErr:  false,
+		},
+		{
+			testName: "file",
+			schema:   "testdata/schema.json",
+			wantErr:  false,
+		},
+		{
+			testName: "missing_file",
+			schema:   "testdata/missing.json",
+			wantErr:  true,
+		},
+		{
+			testName: "invalid_json",
+			schema:   `{"type": `,
+			wantErr:  true,
+		},
+		{
+			testName: "invalid_schema",
+			schema:   `{"type": "foo"}`,
+			wantErr:  true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := script.LoadSchema(d.schema)
+			if (err != nil) != d.wantErr {
+				t.Errorf("unexpected error state: %v", err)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	sch, err := script.LoadSchema(`{
+  "type": "object",
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "string"},
+    "ports": {"type": "array", "items": {"type": "integer", "maximum": 65535}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, d := range []struct {
+		testName string
+		value    any
+		want     []script.SchemaViolation
+	}{
+		{
+			testName: "valid",
+			value:    map[string]any{"id": "a", "ports": []any{json.Number("80"), json.Number("443")}},
+			want:     nil,
+		},
+		{
+			testName: "missing_property",
+			value:    map[string]any{},
+			want:     []script.SchemaViolation{{Location: "", Message: "missing property 'id'"}},
+		},
+		{
+			testName: "multiple_violations",
+			value:    map[string]any{"id": json.Number("1"), "ports": []any{json.Number("80"), json.Number("70000")}},
+			want: []script.SchemaViolation{
+				{Location: "id", Message: "got number, want string"},
+				{Location: "ports[1]", Message: "maximum: got 70,000, want 65,535"},
+			},
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := sch.Validate(d.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(d.want, got); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaViolation_Path(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName      string
+		location      string
+		wantPath      string
+		wantAttribute path.Path
+	}{
+		{
+			testName:      "root",
+			location:      "",
+			wantPath:      "output",
+			wantAttribute: path.Root("output"),
+		},
+		{
+			testName:      "list_index",
+			location:      "ports[1]",
+			wantPath:      "output.ports[1]",
+			wantAttribute: path.Root("output").AtName("ports").AtListIndex(1),
+		},
+		{
+			testName:      "root_list_index",
+			location:      "[0].id",
+			wantPath:      "output[0].id",
+			wantAttribute: path.Root("output").AtListIndex(0).AtName("id"),
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			v := script.SchemaViolation{Location: d.location}
+
+			if got := v.Path("output"); got != d.wantPath {
+				t.Errorf("expected %s, got %s", d.wantPath, got)
+			}
+
+			if got := v.AttributePath("output"); !got.Equal(d.wantAttribute) {
+				t.Errorf("expected %s, got %s", d.wantAttribute, got)
+			}
+		})
+	}
+}
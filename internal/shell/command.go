@@ -4,9 +4,13 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
 	"regexp"
+	"strings"
+	"time"
 )
 
 type Logger interface {
@@ -17,20 +21,42 @@ type Logger interface {
 	Trace(ctx context.Context, msg string, additionalFields ...map[string]any)
 }
 
+// EnvironmentMode defines how the OS environment is inherited by a command.
+type EnvironmentMode string
+
+const (
+	EnvironmentModeAll       EnvironmentMode = "all"
+	EnvironmentModeNone      EnvironmentMode = "none"
+	EnvironmentModeAllowlist EnvironmentMode = "allowlist"
+)
+
+// InheritEnvironment describes which OS environment variables are inherited by a command; the zero value inherits all
+// variables.
+type InheritEnvironment struct {
+	Mode        EnvironmentMode `json:"mode"`
+	Passthrough []string        `json:"passthrough"`
+}
+
 // LogProvider provides a logger for the shell package.
 type LogProvider struct {
 	Logger Logger
 }
 
-// RunCommand runs a script in a given working directory.
-func RunCommand(ctx context.Context, interpreter []string, env map[string]string, dir, command string, logProvider *LogProvider) error {
+// RunCommand runs a script in a given working directory with an optional stdin; if stdout is set the script stdout is
+// written to it instead of being logged. If the context is done the script is terminated, and killed if it hasn't
+// exited after the grace period.
+func RunCommand(ctx context.Context, interpreter []string, env map[string]string, inherit InheritEnvironment, dir, command string, stdin io.Reader, stdout io.Writer, gracePeriod time.Duration, logProvider *LogProvider) error {
 	cmd := exec.CommandContext(ctx, interpreter[0], append(interpreter[1:], command)...)
 	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+
+	stopTermination := configureTermination(cmd, gracePeriod)
+	defer stopTermination()
 
-	setEnv(cmd, env, true)
+	setEnv(cmd, env, inheritedEnviron(os.Environ(), inherit))
 
 	if logProvider == nil {
-		cmd.Stdout = nil
 		cmd.Stderr = nil
 
 		return cmd.Run()
@@ -39,27 +65,70 @@ func RunCommand(ctx context.Context, interpreter []string, env map[string]string
 	return runCommandLogOutput(ctx, cmd, logProvider.Logger)
 }
 
-// setEnv sets the environment variables for a command.
-func setEnv(cmd *exec.Cmd, env map[string]string, addOS bool) {
-	envList := make([]string, 0, len(env))
+// setEnv sets the environment variables for a command on top of the inherited OS environment.
+func setEnv(cmd *exec.Cmd, env map[string]string, osEnv []string) {
+	envList := make([]string, 0, len(osEnv)+len(env))
+	envList = append(envList, osEnv...)
 	for k, v := range env {
 		envList = append(envList, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	if addOS {
-		cmd.Env = append(os.Environ(), envList...)
-	} else {
-		cmd.Env = envList
+	cmd.Env = envList
+}
+
+// inheritedEnviron returns the subset of the OS environment to be inherited by a command.
+func inheritedEnviron(environ []string, inherit InheritEnvironment) []string {
+	switch inherit.Mode {
+	case EnvironmentModeNone:
+		return []string{}
+	case EnvironmentModeAllowlist:
+		inherited := make([]string, 0, len(inherit.Passthrough))
+		for _, kv := range environ {
+			name, _, _ := strings.Cut(kv, "=")
+			if matchEnvironmentPattern(inherit.Passthrough, name) {
+				inherited = append(inherited, kv)
+			}
+		}
+		return inherited
+	default:
+		return environ
 	}
 }
 
-// runCommandLogOutput runs a command and logs the output prefixed with [<LEVEL>].
+// ValidateEnvironmentPattern validates an environment variable glob pattern.
+func ValidateEnvironmentPattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// matchEnvironmentPattern returns true if the environment variable name matches any of the glob patterns.
+func matchEnvironmentPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runCommandLogOutput runs a command and logs the output prefixed with [<LEVEL>]; if the command stdout is already set
+// only stderr is logged, otherwise stderr is merged into stdout.
 func runCommandLogOutput(ctx context.Context, cmd *exec.Cmd, logger Logger) error {
-	pipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
+	var pipe io.ReadCloser
+	var err error
+	if cmd.Stdout != nil {
+		pipe, err = cmd.StderrPipe()
+		if err != nil {
+			return err
+		}
+	} else {
+		pipe, err = cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		cmd.Stderr = cmd.Stdout
 	}
-	cmd.Stderr = cmd.Stdout
 
 	scanner := bufio.NewScanner(pipe)
 	err = cmd.Start()
@@ -1,10 +1,12 @@
 package shell
 
 import (
+	"bytes"
 	"context"
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 )
 
 type testLogger struct {
@@ -184,7 +186,7 @@ func TestRunCommand(t *testing.T) {
 			}
 
 			ctx := t.Context()
-			err := RunCommand(ctx, d.interpreter, d.env, d.dir, d.command, d.logProvider)
+			err := RunCommand(ctx, d.interpreter, d.env, InheritEnvironment{}, d.dir, d.command, nil, nil, time.Second, d.logProvider)
 
 			hasErr := err != nil
 			if hasErr != d.hasErr {
@@ -217,3 +219,106 @@ func TestRunCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestRunCommand_Stdout(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Test is not valid on Windows")
+	}
+
+	for _, d := range []struct {
+		testName     string
+		command      string
+		logProvider  *LogProvider
+		wantStdout   string
+		loggerResult *testLogger
+	}{
+		{
+			testName:   "no_log",
+			command:    `echo '{"a": 1}'; echo "[INFO] Test info" >&2`,
+			wantStdout: "{\"a\": 1}\n",
+		},
+		{
+			testName:     "log_stderr",
+			command:      `echo '{"a": 1}'; echo "[INFO] Test info" >&2; echo "[WARN] Test warn"`,
+			logProvider:  &LogProvider{Logger: &testLogger{}},
+			wantStdout:   "{\"a\": 1}\n[WARN] Test warn\n",
+			loggerResult: &testLogger{infos: []string{"Test info"}},
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+			err := RunCommand(t.Context(), []string{"/bin/bash", "-c"}, nil, InheritEnvironment{}, "", d.command, nil, &stdout, time.Second, d.logProvider)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if stdout.String() != d.wantStdout {
+				t.Errorf("expected stdout %q, got %q", d.wantStdout, stdout.String())
+			}
+
+			if d.logProvider != nil {
+				logger, _ := d.logProvider.Logger.(*testLogger)
+
+				if !reflect.DeepEqual(logger, d.loggerResult) {
+					t.Errorf("expected logs %+v, got %+v", d.loggerResult, logger)
+				}
+			}
+		})
+	}
+}
+
+func TestInheritedEnviron(t *testing.T) {
+	t.Parallel()
+
+	environ := []string{"HOME=/home/test", "PATH=/usr/bin", "AWS_ACCESS_KEY_ID=xxx", "AWS_REGION=eu-west-1", "EMPTY="}
+
+	for _, d := range []struct {
+		testName string
+		inherit  InheritEnvironment
+		want     []string
+	}{
+		{
+			testName: "zero_value",
+			inherit:  InheritEnvironment{},
+			want:     environ,
+		},
+		{
+			testName: "all",
+			inherit:  InheritEnvironment{Mode: EnvironmentModeAll, Passthrough: []string{"PATH"}},
+			want:     environ,
+		},
+		{
+			testName: "none",
+			inherit:  InheritEnvironment{Mode: EnvironmentModeNone, Passthrough: []string{"PATH"}},
+			want:     []string{},
+		},
+		{
+			testName: "allowlist_names",
+			inherit:  InheritEnvironment{Mode: EnvironmentModeAllowlist, Passthrough: []string{"HOME", "PATH", "EMPTY"}},
+			want:     []string{"HOME=/home/test", "PATH=/usr/bin", "EMPTY="},
+		},
+		{
+			testName: "allowlist_glob",
+			inherit:  InheritEnvironment{Mode: EnvironmentModeAllowlist, Passthrough: []string{"AWS_*"}},
+			want:     []string{"AWS_ACCESS_KEY_ID=xxx", "AWS_REGION=eu-west-1"},
+		},
+		{
+			testName: "allowlist_empty",
+			inherit:  InheritEnvironment{Mode: EnvironmentModeAllowlist},
+			want:     []string{},
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			got := inheritedEnviron(environ, d.inherit)
+			if !reflect.DeepEqual(got, d.want) {
+				t.Errorf("expected %v, got %v", d.want, got)
+			}
+		})
+	}
+}
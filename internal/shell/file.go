@@ -1,7 +1,6 @@
 package shell
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -17,8 +16,18 @@ func GetErrorFilePath() (string, error) {
 	return getTempFile("tf-script-error-*")
 }
 
+// GetInputFilePath returns the path to a file for passing the named input to a command.
+func GetInputFilePath(name string) (string, error) {
+	return getTempFile(fmt.Sprintf("tf-script-%s-*.json", name))
+}
+
 // ReadJSON reads a file as JSON and returns the contents.
 func ReadJSON(p string) (any, error) {
+	return ReadOutput(p, OutputFormatJSON)
+}
+
+// ReadOutput reads a file in the given format and returns the contents.
+func ReadOutput(p string, format OutputFormat) (any, error) {
 	f, err := os.Open(p)
 	if err != nil {
 		return nil, err
@@ -30,17 +39,7 @@ func ReadJSON(p string) (any, error) {
 		return nil, err
 	}
 
-	if !json.Valid(b) {
-		return nil, fmt.Errorf("file is not valid JSON")
-	}
-
-	var r any
-	err = json.Unmarshal(b, &r)
-	if err != nil {
-		return nil, err
-	}
-
-	return r, nil
+	return ParseOutput(b, format)
 }
 
 // getTempFile creates a temporary file and returns the path.
@@ -0,0 +1,289 @@
+package shell
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goccy/go-yaml"
+)
+
+// OutputFormat defines the format of a command output.
+type OutputFormat string
+
+const (
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatTOML   OutputFormat = "toml"
+	OutputFormatDotenv OutputFormat = "dotenv"
+	OutputFormatRaw    OutputFormat = "raw"
+)
+
+// ParseError describes an output which couldn't be parsed; the line and column start at 1 and are 0 if not known.
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the error message prefixed with the position.
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// dotenvKeyRegex matches a valid dotenv key.
+var dotenvKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ParseOutput parses the bytes in the given format and returns the contents in the same form as decoded JSON; an empty
+// format is treated as JSON.
+func ParseOutput(b []byte, format OutputFormat) (any, error) {
+	switch format {
+	case "", OutputFormatJSON:
+		return parseJSON(b)
+	case OutputFormatYAML:
+		return parseYAML(b)
+	case OutputFormatTOML:
+		return parseTOML(b)
+	case OutputFormatDotenv:
+		return parseDotenv(b)
+	case OutputFormatRaw:
+		return parseRaw(b), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// parseJSON parses the bytes as JSON; numbers are returned as json.Number to preserve their precision.
+func parseJSON(b []byte) (any, error) {
+	// Validate the input first as json.Unmarshal reports the offset of any syntax error in the whole input.
+	if err := json.Unmarshal(b, &json.RawMessage{}); err != nil {
+		syntaxErr := &json.SyntaxError{}
+		if errors.As(err, &syntaxErr) {
+			line, column := offsetPosition(b, syntaxErr.Offset)
+			return nil, &ParseError{Line: line, Column: column, Err: err}
+		}
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var r any
+	if err := dec.Decode(&r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// parseYAML parses the bytes as a single YAML document.
+func parseYAML(b []byte) (any, error) {
+	var r any
+	if err := yaml.Unmarshal(b, &r); err != nil {
+		var yamlErr yaml.Error
+		if errors.As(err, &yamlErr) && yamlErr.GetToken() != nil {
+			pos := yamlErr.GetToken().Position
+			return nil, &ParseError{Line: pos.Line, Column: pos.Column, Err: errors.New(yamlErr.GetMessage())}
+		}
+		return nil, err
+	}
+
+	return normalizeOutput(r)
+}
+
+// parseTOML parses the bytes as a TOML document.
+func parseTOML(b []byte) (any, error) {
+	var r map[string]any
+	if err := toml.Unmarshal(b, &r); err != nil {
+		parseErr := toml.ParseError{}
+		if errors.As(err, &parseErr) {
+			return nil, &ParseError{Line: parseErr.Position.Line, Column: parseErr.Position.Col, Err: errors.New(parseErr.Message)}
+		}
+		return nil, err
+	}
+
+	return normalizeOutput(r)
+}
+
+// parseDotenv parses the bytes as KEY=VALUE lines into an object of strings; blank lines, # comments and export
+// prefixes are ignored and values can be single or double quoted.
+func parseDotenv(b []byte) (any, error) {
+	r := map[string]any{}
+
+	for i, line := range strings.Split(string(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		column := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return nil, &ParseError{Line: i + 1, Column: column, Err: errors.New("expected KEY=VALUE")}
+		}
+
+		key = strings.TrimSpace(key)
+		if !dotenvKeyRegex.MatchString(key) {
+			return nil, &ParseError{Line: i + 1, Column: column, Err: fmt.Errorf("invalid key: %q", key)}
+		}
+
+		valueColumn := strings.Index(line, "=") + 2
+		value, err := parseDotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, &ParseError{Line: i + 1, Column: valueColumn, Err: err}
+		}
+
+		r[key] = value
+	}
+
+	return r, nil
+}
+
+// parseDotenvValue parses a dotenv value, which is either quoted or ends at an inline comment.
+func parseDotenvValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '"':
+				if rest := strings.TrimSpace(s[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+					return "", errors.New("unexpected characters after quoted value")
+				}
+				return sb.String(), nil
+			case '\\':
+				if i+1 < len(s) {
+					i++
+					switch s[i] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					default:
+						sb.WriteByte(s[i])
+					}
+				}
+			default:
+				sb.WriteByte(s[i])
+			}
+		}
+		return "", errors.New("unterminated double quoted value")
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quoted value")
+		}
+		if rest := strings.TrimSpace(s[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", errors.New("unexpected characters after quoted value")
+		}
+		return s[1 : end+1], nil
+	default:
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), nil
+	}
+}
+
+// parseRaw returns the bytes as a string with a single trailing newline removed, as written by commands such as echo.
+func parseRaw(b []byte) string {
+	s := string(b)
+	if t, ok := strings.CutSuffix(s, "\n"); ok {
+		return strings.TrimSuffix(t, "\r")
+	}
+
+	return s
+}
+
+// normalizeOutput converts the values produced by the YAML and TOML parsers to the types produced by decoding JSON;
+// integers are converted to json.Number to preserve their precision.
+func normalizeOutput(v any) (any, error) {
+	switch x := v.(type) {
+	case nil, bool, string, float64:
+		return x, nil
+	case int:
+		return json.Number(strconv.Itoa(x)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(x, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(x, 10)), nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	case []any:
+		r := make([]any, len(x))
+		for i, e := range x {
+			n, err := normalizeOutput(e)
+			if err != nil {
+				return nil, err
+			}
+			r[i] = n
+		}
+		return r, nil
+	case []map[string]any:
+		r := make([]any, len(x))
+		for i, e := range x {
+			n, err := normalizeOutput(e)
+			if err != nil {
+				return nil, err
+			}
+			r[i] = n
+		}
+		return r, nil
+	case map[string]any:
+		r := make(map[string]any, len(x))
+		for k, e := range x {
+			n, err := normalizeOutput(e)
+			if err != nil {
+				return nil, err
+			}
+			r[k] = n
+		}
+		return r, nil
+	case map[any]any:
+		r := make(map[string]any, len(x))
+		for k, e := range x {
+			n, err := normalizeOutput(e)
+			if err != nil {
+				return nil, err
+			}
+			r[fmt.Sprint(k)] = n
+		}
+		return r, nil
+	case fmt.Stringer:
+		return x.String(), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %T", v)
+	}
+}
+
+// offsetPosition returns the line and column of the last byte read before the offset, both starting at 1.
+func offsetPosition(b []byte, offset int64) (int, int) {
+	pos := int(min(max(offset-1, 0), int64(len(b))))
+	before := b[:pos]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := pos - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,128 @@
+package shell
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		format   OutputFormat
+		input    string
+		expected any
+		errMsg   string
+	}{
+		{
+			testName: "default_json",
+			format:   "",
+			input:    `{"foo": "bar", "num": 1}`,
+			expected: map[string]any{"foo": "bar", "num": json.Number("1")},
+		},
+		{
+			testName: "json_error",
+			format:   OutputFormatJSON,
+			input:    "{\n  \"foo\": bar\n}",
+			errMsg:   "line 2, column 10: invalid character 'b' looking for beginning of value",
+		},
+		{
+			testName: "json_empty",
+			format:   OutputFormatJSON,
+			input:    "",
+			errMsg:   "line 1, column 1: unexpected end of JSON input",
+		},
+		{
+			testName: "yaml",
+			format:   OutputFormatYAML,
+			input:    "foo: bar\nnum: 1\nfloat: 1.5\nlist:\n  - a\n  - true\n1: one\nempty:\n",
+			expected: map[string]any{"foo": "bar", "num": json.Number("1"), "float": 1.5, "list": []any{"a", true}, "1": "one", "empty": nil},
+		},
+		{
+			testName: "yaml_error",
+			format:   OutputFormatYAML,
+			input:    "foo: bar\nbaz: [\n",
+			errMsg:   "line 2, column 6: sequence end token ']' not found",
+		},
+		{
+			testName: "yaml_error_column",
+			format:   OutputFormatYAML,
+			input:    "foo: bar\nbaz: qux: 1\n",
+			errMsg:   "line 2, column 6: mapping value is not allowed in this context",
+		},
+		{
+			testName: "toml",
+			format:   OutputFormatTOML,
+			input:    "foo = \"bar\"\nnum = 1\ndate = 2024-01-02T03:04:05Z\n\n[[items]]\nname = \"a\"\n",
+			expected: map[string]any{"foo": "bar", "num": json.Number("1"), "date": "2024-01-02T03:04:05Z", "items": []any{map[string]any{"name": "a"}}},
+		},
+		{
+			testName: "toml_error",
+			format:   OutputFormatTOML,
+			input:    "foo = \"bar\"\nbaz = \n",
+			errMsg:   "line 2, column 7: expected value but found '\\n' instead",
+		},
+		{
+			testName: "dotenv",
+			format:   OutputFormatDotenv,
+			input:    "# comment\nFOO=bar\nexport BAZ = \"a\\nb\" # comment\n\nQUX='c # d'\nEMPTY=\nINLINE=value # comment\r\n",
+			expected: map[string]any{"FOO": "bar", "BAZ": "a\nb", "QUX": "c # d", "EMPTY": "", "INLINE": "value"},
+		},
+		{
+			testName: "dotenv_missing_equals",
+			format:   OutputFormatDotenv,
+			input:    "FOO=bar\n  BAZ\n",
+			errMsg:   "line 2, column 3: expected KEY=VALUE",
+		},
+		{
+			testName: "dotenv_unterminated",
+			format:   OutputFormatDotenv,
+			input:    "FOO=\"bar\n",
+			errMsg:   "line 1, column 5: unterminated double quoted value",
+		},
+		{
+			testName: "raw",
+			format:   OutputFormatRaw,
+			input:    "hello\nworld\n\n",
+			expected: "hello\nworld\n",
+		},
+		{
+			testName: "raw_crlf",
+			format:   OutputFormatRaw,
+			input:    "hello\r\n",
+			expected: "hello",
+		},
+		{
+			testName: "raw_no_newline",
+			format:   OutputFormatRaw,
+			input:    "hello",
+			expected: "hello",
+		},
+		{
+			testName: "unsupported",
+			format:   "xml",
+			input:    "<foo/>",
+			errMsg:   "unsupported output format: xml",
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := ParseOutput([]byte(d.input), d.format)
+
+			if !reflect.DeepEqual(raw, d.expected) {
+				t.Errorf("expected %#v, got %#v", d.expected, raw)
+			}
+
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.errMsg {
+				t.Errorf("expected error %q, got %q", d.errMsg, errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !windows
+
+package shell
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// processGroupPollInterval is how often the process group is checked for remaining processes once the command has
+// exited during its grace period.
+const processGroupPollInterval = 50 * time.Millisecond
+
+// configureTermination starts the command in its own process group so that when the context is done SIGTERM is sent to
+// the whole group, followed by SIGKILL once the grace period has elapsed. The returned function must be called once
+// the command has been waited for; processes left in the group keep the rest of the grace period, and the pending
+// SIGKILL is only stopped once the group is empty so it can't hit a process group which has reused the id.
+func configureTermination(cmd *exec.Cmd, gracePeriod time.Duration) func() {
+	var mu sync.Mutex
+	var timer *time.Timer
+	var deadline time.Time
+	var pgid int
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		pgid = -cmd.Process.Pid
+		deadline = time.Now().Add(gracePeriod)
+		timer = time.AfterFunc(gracePeriod, func() {
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+		})
+
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = gracePeriod
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if timer == nil {
+			return
+		}
+
+		for time.Now().Before(deadline) {
+			if errors.Is(syscall.Kill(pgid, 0), syscall.ESRCH) {
+				timer.Stop()
+				return
+			}
+
+			time.Sleep(processGroupPollInterval)
+		}
+
+		if timer.Stop() {
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//go:build !windows
+
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunCommand_Termination(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName    string
+		command     string
+		gracePeriod time.Duration
+		minElapsed  time.Duration
+		wantTrap    bool
+	}{
+		{
+			testName:    "sigterm",
+			command:     `trap 'printf trapped > "${TRAP_FILE}"; exit 1' TERM; sleep 30 & printf '%s' "$!" > "${PID_FILE}"; wait`,
+			gracePeriod: 5 * time.Second,
+			wantTrap:    true,
+		},
+		{
+			testName:    "sigkill_after_grace_period",
+			command:     `trap '' TERM; sleep 30 & printf '%s' "$!" > "${PID_FILE}"; wait`,
+			gracePeriod: 100 * time.Millisecond,
+			wantTrap:    false,
+		},
+		{
+			testName:    "grace_period_after_exit",
+			command:     `trap 'exit 1' TERM; (trap 'sleep 1; printf trapped > "${TRAP_FILE}"; exit 0' TERM; sleep 30 & wait) & printf '%s' "$!" > "${PID_FILE}"; wait`,
+			gracePeriod: 30 * time.Second,
+			wantTrap:    true,
+		},
+		{
+			testName:    "sigkill_after_exit",
+			command:     `trap 'exit 1' TERM; (trap '' TERM; sleep 30) & printf '%s' "$!" > "${PID_FILE}"; wait`,
+			gracePeriod: time.Second,
+			minElapsed:  time.Second,
+			wantTrap:    false,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			pidFile := path.Join(dir, "pid")
+			trapFile := path.Join(dir, "trap")
+
+			ctx, cancel := context.WithTimeout(t.Context(), 500*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := RunCommand(ctx, []string{"/bin/bash", "-c"}, map[string]string{"PID_FILE": pidFile, "TRAP_FILE": trapFile}, InheritEnvironment{}, "", d.command, nil, nil, d.gracePeriod, nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			elapsed := time.Since(start)
+			if elapsed > 5*time.Second {
+				t.Errorf("expected command to be stopped, took: %v", elapsed)
+			}
+
+			if elapsed < d.minElapsed {
+				t.Errorf("expected the grace period to be waited for, took: %v", elapsed)
+			}
+
+			_, err = os.Stat(trapFile)
+			if trapped := err == nil; trapped != d.wantTrap {
+				t.Errorf("expected trapped=%v, got: %v", d.wantTrap, trapped)
+			}
+
+			by, err := os.ReadFile(pidFile)
+			if err != nil {
+				t.Fatalf("failed to read child pid: %v", err)
+			}
+
+			pid, err := strconv.Atoi(strings.TrimSpace(string(by)))
+			if err != nil {
+				t.Fatalf("failed to parse child pid: %v", err)
+			}
+
+			// The child process is reaped asynchronously once it has been killed.
+			for range 50 {
+				if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			t.Errorf("expected child process %d to be killed", pid)
+		})
+	}
+}
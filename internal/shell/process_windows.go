@@ -0,0 +1,16 @@
+//go:build windows
+
+package shell
+
+import (
+	"os/exec"
+	"time"
+)
+
+// configureTermination kills the command when the context is done, waiting up to the grace period for its output to be
+// closed; process groups can't be signalled on Windows. The returned function does nothing.
+func configureTermination(cmd *exec.Cmd, gracePeriod time.Duration) func() {
+	cmd.WaitDelay = gracePeriod
+
+	return func() {}
+}
@@ -2,3 +2,6 @@ package tfdynamic
 
 // UnknownStringLiteral is a marker string used to indicate that a string value should be treated as an unknown type.
 const UnknownStringLiteral = "???"
+
+// numberPrecision is the precision in bits used for decoded numbers; this matches the precision used by Terraform.
+const numberPrecision = 512
@@ -0,0 +1,310 @@
+package tfdynamic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ValidateType validates a Terraform type constraint such as object({id=string, tags=optional(map(string))}).
+func ValidateType(constraint string) error {
+	_, _, err := parseType(constraint)
+	return err
+}
+
+// parseType parses a Terraform type constraint and returns the type and any optional attribute defaults.
+func parseType(constraint string) (cty.Type, *typeexpr.Defaults, error) {
+	expr, hclDiags := hclsyntax.ParseExpression([]byte(constraint), "output_type", hcl.InitialPos)
+	if hclDiags.HasErrors() {
+		return cty.NilType, nil, hclDiags
+	}
+
+	ty, defaults, hclDiags := typeexpr.TypeConstraintWithDefaults(expr)
+	if hclDiags.HasErrors() {
+		return cty.NilType, nil, hclDiags
+	}
+
+	return ty, defaults, nil
+}
+
+// decodeTyped decodes an object into a Terraform attribute value after converting it to the type option; optional
+// attributes which are missing are set to their default or null.
+func decodeTyped(ctx context.Context, obj any, opts DecodeOptions) (types.Dynamic, diag.Diagnostics) {
+	diags := diag.Diagnostics{}
+
+	ty, defaults, err := parseType(opts.Type)
+	if err != nil {
+		diags.AddError("Invalid output type.", err.Error())
+		return types.Dynamic{}, diags
+	}
+
+	val, err := ctyValue(obj, opts)
+	if err != nil {
+		diags.AddError("Unexpected type.", err.Error())
+		return types.Dynamic{}, diags
+	}
+
+	if defaults != nil {
+		val = defaults.Apply(val)
+	}
+
+	val, err = convert.Convert(val, ty)
+	if err != nil {
+		// Type mismatches describe their path in the message while value errors carry the path separately.
+		var p cty.Path
+		pathErr := cty.PathError{}
+		if errors.As(err, &pathErr) {
+			p = pathErr.Path
+		}
+		diags.AddAttributeError(ctyAttributePath(path.Root("output"), p), "Output doesn't match the output type.", err.Error())
+		return types.Dynamic{}, diags
+	}
+
+	v, err := attrValue(ctx, val)
+	if err != nil {
+		diags.AddError("Failed to decode output.", err.Error())
+		return types.Dynamic{}, diags
+	}
+
+	if d, ok := v.(types.Dynamic); ok {
+		return d, diags
+	}
+
+	return types.DynamicValue(v), diags
+}
+
+// ctyValue converts an object into a cty value; Unknown and, unless disabled, the unknown string literal mark unknown
+// values.
+func ctyValue(obj any, opts DecodeOptions) (cty.Value, error) {
+	switch v := obj.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case string:
+		if v == UnknownStringLiteral && !opts.DisableUnknownStringLiteral {
+			return cty.DynamicVal, nil
+		}
+		return cty.StringVal(v), nil
+	case unknownValue:
+		return cty.DynamicVal, nil
+	case int64:
+		return cty.NumberIntVal(v), nil
+	case float64:
+		return cty.NumberFloatVal(v), nil
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, numberPrecision, big.ToNearestEven)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("invalid number %q: %w", v.String(), err)
+		}
+		return cty.NumberVal(f), nil
+	case *big.Float:
+		return cty.NumberVal(v), nil
+	case []any:
+		vals := make([]cty.Value, len(v))
+		for i, e := range v {
+			ev, err := ctyValue(e, opts)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = ev
+		}
+		return cty.TupleVal(vals), nil
+	case map[string]any:
+		vals := make(map[string]cty.Value, len(v))
+		for k, e := range v {
+			ev, err := ctyValue(e, opts)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[k] = ev
+		}
+		return cty.ObjectVal(vals), nil
+	default:
+		return cty.NilVal, fmt.Errorf("unexpected type: %T for value %#v", v, v)
+	}
+}
+
+// attrValue converts a cty value into a Terraform attribute value.
+func attrValue(ctx context.Context, val cty.Value) (attr.Value, error) {
+	ty := val.Type()
+
+	if !val.IsKnown() || val.IsNull() {
+		if ty == cty.DynamicPseudoType {
+			if !val.IsKnown() {
+				return types.DynamicUnknown(), nil
+			}
+			return types.DynamicNull(), nil
+		}
+
+		t, err := attrType(ty)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := tftypes.NewValue(t.TerraformType(ctx), nil)
+		if !val.IsKnown() {
+			raw = tftypes.NewValue(t.TerraformType(ctx), tftypes.UnknownValue)
+		}
+		return t.ValueFromTerraform(ctx, raw)
+	}
+
+	switch {
+	case ty == cty.String:
+		return types.StringValue(val.AsString()), nil
+	case ty == cty.Number:
+		return types.NumberValue(val.AsBigFloat()), nil
+	case ty == cty.Bool:
+		return types.BoolValue(val.True()), nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		elems := make([]attr.Value, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			e, err := attrValue(ctx, ev)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, e)
+		}
+
+		t, err := attrType(ty)
+		if err != nil {
+			return nil, err
+		}
+
+		var diags diag.Diagnostics
+		var v attr.Value
+		switch t := t.(type) {
+		case types.ListType:
+			v, diags = types.ListValue(t.ElemType, elems)
+		case types.SetType:
+			v, diags = types.SetValue(t.ElemType, elems)
+		case types.TupleType:
+			v, diags = types.TupleValue(t.ElemTypes, elems)
+		}
+		return v, diagsError(diags)
+	case ty.IsMapType() || ty.IsObjectType():
+		elems := make(map[string]attr.Value, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			e, err := attrValue(ctx, ev)
+			if err != nil {
+				return nil, err
+			}
+			elems[k.AsString()] = e
+		}
+
+		t, err := attrType(ty)
+		if err != nil {
+			return nil, err
+		}
+
+		var diags diag.Diagnostics
+		var v attr.Value
+		switch t := t.(type) {
+		case types.MapType:
+			v, diags = types.MapValue(t.ElemType, elems)
+		case types.ObjectType:
+			v, diags = types.ObjectValue(t.AttrTypes, elems)
+		}
+		return v, diagsError(diags)
+	default:
+		return nil, fmt.Errorf("unexpected type: %s", ty.FriendlyName())
+	}
+}
+
+// attrType converts a cty type into a Terraform attribute type.
+func attrType(ty cty.Type) (attr.Type, error) {
+	switch {
+	case ty == cty.DynamicPseudoType:
+		return types.DynamicType, nil
+	case ty == cty.String:
+		return types.StringType, nil
+	case ty == cty.Number:
+		return types.NumberType, nil
+	case ty == cty.Bool:
+		return types.BoolType, nil
+	case ty.IsListType():
+		et, err := attrType(ty.ElementType())
+		return types.ListType{ElemType: et}, err
+	case ty.IsSetType():
+		et, err := attrType(ty.ElementType())
+		return types.SetType{ElemType: et}, err
+	case ty.IsMapType():
+		et, err := attrType(ty.ElementType())
+		return types.MapType{ElemType: et}, err
+	case ty.IsTupleType():
+		ets := make([]attr.Type, len(ty.TupleElementTypes()))
+		for i, t := range ty.TupleElementTypes() {
+			et, err := attrType(t)
+			if err != nil {
+				return nil, err
+			}
+			ets[i] = et
+		}
+		return types.TupleType{ElemTypes: ets}, nil
+	case ty.IsObjectType():
+		ats := make(map[string]attr.Type, len(ty.AttributeTypes()))
+		for k, t := range ty.AttributeTypes() {
+			at, err := attrType(t)
+			if err != nil {
+				return nil, err
+			}
+			ats[k] = at
+		}
+		return types.ObjectType{AttrTypes: ats}, nil
+	default:
+		return nil, fmt.Errorf("unexpected type: %s", ty.FriendlyName())
+	}
+}
+
+// ctyAttributePath converts a cty path into an attribute path under the root; numeric indexes are treated as list
+// indexes.
+func ctyAttributePath(root path.Path, p cty.Path) path.Path {
+	for _, step := range p {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			root = root.AtName(s.Name)
+		case cty.IndexStep:
+			switch {
+			case s.Key.Type() == cty.String && s.Key.IsKnown():
+				root = root.AtMapKey(s.Key.AsString())
+			case s.Key.Type() == cty.Number && s.Key.IsKnown():
+				i, _ := s.Key.AsBigFloat().Int64()
+				root = root.AtListIndex(int(i))
+			default:
+				return root
+			}
+		}
+	}
+
+	return root
+}
+
+// diagsError returns the diagnostics errors as a single error or nil if there are none.
+func diagsError(diags diag.Diagnostics) error {
+	if !diags.HasError() {
+		return nil
+	}
+
+	msgs := make([]string, 0, diags.ErrorsCount())
+	for _, d := range diags.Errors() {
+		msgs = append(msgs, fmt.Sprintf("%s %s", d.Summary(), d.Detail()))
+	}
+
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,187 @@
+package tfdynamic
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateType(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName   string
+		constraint string
+		hasErr     bool
+	}{
+		{
+			testName:   "primitive",
+			constraint: "string",
+			hasErr:     false,
+		},
+		{
+			testName:   "object_with_optional",
+			constraint: `object({id=string, tags=optional(map(string), {})})`,
+			hasErr:     false,
+		},
+		{
+			testName:   "any",
+			constraint: "list(any)",
+			hasErr:     false,
+		},
+		{
+			testName:   "unknown_type",
+			constraint: "strin",
+			hasErr:     true,
+		},
+		{
+			testName:   "invalid_syntax",
+			constraint: "object({",
+			hasErr:     true,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateType(d.constraint)
+
+			hasErr := err != nil
+			if hasErr != d.hasErr {
+				t.Errorf("unexpected error state: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeWithOptions_Type(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	tagsType := types.MapType{ElemType: types.StringType}
+	tags, _ := types.MapValue(types.StringType, map[string]attr.Value{"env": types.StringValue("dev")})
+	object, _ := types.ObjectValue(
+		map[string]attr.Type{"id": types.StringType, "count": types.NumberType, "tags": tagsType, "note": types.StringType},
+		map[string]attr.Value{"id": types.StringValue("123"), "count": types.NumberValue(big.NewFloat(2)), "tags": tags, "note": types.StringNull()},
+	)
+	objectDefault, _ := types.ObjectValue(
+		map[string]attr.Type{"id": types.StringType, "tags": tagsType},
+		map[string]attr.Value{"id": types.StringValue("a"), "tags": types.MapValueMust(types.StringType, map[string]attr.Value{})},
+	)
+	objectUnknown, _ := types.ObjectValue(
+		map[string]attr.Type{"id": types.StringType},
+		map[string]attr.Value{"id": types.StringUnknown()},
+	)
+	numberSet, _ := types.SetValue(types.NumberType, []attr.Value{types.NumberValue(big.NewFloat(1)), types.NumberValue(big.NewFloat(2))})
+
+	for _, d := range []struct {
+		testName  string
+		obj       any
+		typ       string
+		expected  types.Dynamic
+		errMsg    string
+		errDetail string
+		errPath   path.Path
+	}{
+		{
+			testName: "object",
+			obj:      map[string]any{"id": json.Number("123"), "count": "2", "tags": map[string]any{"env": "dev"}},
+			typ:      `object({id=string, count=number, tags=map(string), note=optional(string)})`,
+			expected: types.DynamicValue(object),
+		},
+		{
+			testName: "object_default",
+			obj:      map[string]any{"id": "a"},
+			typ:      `object({id=string, tags=optional(map(string), {})})`,
+			expected: types.DynamicValue(objectDefault),
+		},
+		{
+			testName: "object_unknown",
+			obj:      map[string]any{"id": UnknownStringLiteral},
+			typ:      `object({id=string})`,
+			expected: types.DynamicValue(objectUnknown),
+		},
+		{
+			testName: "set",
+			obj:      []any{json.Number("2"), json.Number("1"), json.Number("2")},
+			typ:      "set(number)",
+			expected: types.DynamicValue(numberSet),
+		},
+		{
+			testName:  "missing_attribute",
+			obj:       map[string]any{"tags": map[string]any{}},
+			typ:       `object({id=string, tags=map(string)})`,
+			errMsg:    "Output doesn't match the output type.",
+			errDetail: `attribute "id" is required`,
+			errPath:   path.Root("output"),
+		},
+		{
+			testName:  "invalid_element",
+			obj:       map[string]any{"tags": map[string]any{"env": []any{"a"}}},
+			typ:       `object({tags=map(string)})`,
+			errMsg:    "Output doesn't match the output type.",
+			errDetail: `attribute "tags": element "env": string required, but have tuple`,
+			errPath:   path.Root("output"),
+		},
+		{
+			testName:  "invalid_value",
+			obj:       map[string]any{"tags": map[string]any{"env": "dev"}, "count": "abc"},
+			typ:       `object({tags=map(string), count=number})`,
+			errMsg:    "Output doesn't match the output type.",
+			errDetail: `a number is required`,
+			errPath:   path.Root("output").AtName("count"),
+		},
+		{
+			testName:  "invalid_list_element",
+			obj:       map[string]any{"ports": []any{json.Number("80"), "abc"}},
+			typ:       `object({ports=list(number)})`,
+			errMsg:    "Output doesn't match the output type.",
+			errDetail: `a number is required`,
+			errPath:   path.Root("output").AtName("ports").AtListIndex(1),
+		},
+		{
+			testName: "invalid_type",
+			obj:      "a",
+			typ:      "strin",
+			errMsg:   "Invalid output type.",
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			dyn, diags := DecodeWithOptions(ctx, d.obj, DecodeOptions{Type: d.typ})
+
+			if len(d.errMsg) > 0 {
+				if !diags.HasError() {
+					t.Fatalf("expected error message %s, got none", d.errMsg)
+				}
+
+				if errMsg := diags.Errors()[0].Summary(); errMsg != d.errMsg {
+					t.Errorf("expected error message %s, got %s", d.errMsg, errMsg)
+				}
+
+				if errDetail := diags.Errors()[0].Detail(); len(d.errDetail) > 0 && errDetail != d.errDetail {
+					t.Errorf("expected error detail %s, got %s", d.errDetail, errDetail)
+				}
+
+				if pathDiag, ok := diags.Errors()[0].(diag.DiagnosticWithPath); len(d.errPath.Steps()) > 0 && (!ok || !pathDiag.Path().Equal(d.errPath)) {
+					t.Errorf("expected error path %s, got %v", d.errPath, diags.Errors()[0])
+				}
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if !dyn.Equal(d.expected) {
+				t.Errorf("expected %v, got %v", d.expected, dyn)
+			}
+		})
+	}
+}
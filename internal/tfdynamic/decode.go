@@ -2,21 +2,55 @@ package tfdynamic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
+// CollectionMode defines how sequences and mappings are decoded.
+type CollectionMode string
+
+const (
+	// CollectionModeStructural decodes sequences to tuples and mappings to objects.
+	CollectionModeStructural CollectionMode = "structural"
+	// CollectionModeInfer decodes sequences to lists and mappings to maps if all of their elements have the same type,
+	// falling back to tuples and objects otherwise; a collection containing an unknown value is unknown as its type
+	// can't be inferred.
+	CollectionModeInfer CollectionMode = "infer"
+)
+
+// DecodeOptions describes how an object is decoded; the zero value uses the structural collection mode.
+type DecodeOptions struct {
+	CollectionMode CollectionMode
+	// Type is a Terraform type constraint to convert the object to; if set the collection mode is ignored.
+	Type string
+	// DisableUnknownStringLiteral decodes strings matching UnknownStringLiteral as strings instead of unknown values.
+	DisableUnknownStringLiteral bool
+}
+
 // Decode decodes an object into a Terraform attribute value.
 func Decode(ctx context.Context, obj any) (types.Dynamic, diag.Diagnostics) {
+	return DecodeWithOptions(ctx, obj, DecodeOptions{})
+}
+
+// DecodeWithOptions decodes an object into a Terraform attribute value using the options.
+func DecodeWithOptions(ctx context.Context, obj any, opts DecodeOptions) (types.Dynamic, diag.Diagnostics) {
 	if obj == nil {
 		return types.DynamicNull(), nil
 	}
 
-	v, diags := decodeScalar(ctx, obj)
+	if opts.Type != "" {
+		return decodeTyped(ctx, obj, opts)
+	}
+
+	v, diags := decodeScalar(ctx, obj, opts)
 	if diags.HasError() {
 		return types.Dynamic{}, diags
 	}
@@ -30,25 +64,37 @@ func Decode(ctx context.Context, obj any) (types.Dynamic, diag.Diagnostics) {
 }
 
 // decodeScalar decodes a scalar value into a Terraform attribute value.
-func decodeScalar(ctx context.Context, a any) (attr.Value, diag.Diagnostics) {
+func decodeScalar(ctx context.Context, a any, opts DecodeOptions) (attr.Value, diag.Diagnostics) {
 	switch v := a.(type) {
 	case nil:
 		return types.DynamicNull(), nil
 	case int64:
-		return types.NumberValue(big.NewFloat(float64(v))), nil
+		return types.NumberValue(new(big.Float).SetInt64(v)), nil
 	case float64:
 		return types.NumberValue(big.NewFloat(v)), nil
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, numberPrecision, big.ToNearestEven)
+		if err != nil {
+			diagnostics := diag.Diagnostics{}
+			diagnostics.AddError("Invalid number.", fmt.Sprintf("invalid number %q: %s", v.String(), err))
+			return nil, diagnostics
+		}
+		return types.NumberValue(f), nil
+	case *big.Float:
+		return types.NumberValue(v), nil
 	case bool:
 		return types.BoolValue(v), nil
 	case string:
-		if v == UnknownStringLiteral {
+		if v == UnknownStringLiteral && !opts.DisableUnknownStringLiteral {
 			return types.DynamicUnknown(), nil
 		}
 		return types.StringValue(v), nil
+	case unknownValue:
+		return types.DynamicUnknown(), nil
 	case []any:
-		return decodeSequence(ctx, v)
+		return decodeSequence(ctx, v, opts)
 	case map[string]any:
-		return decodeMapping(ctx, v)
+		return decodeMapping(ctx, v, opts)
 	default:
 		diagnostics := diag.Diagnostics{}
 		diagnostics.AddError("Unexpected type.", fmt.Sprintf("unexpected type: %T for value %#v", v, v))
@@ -57,13 +103,13 @@ func decodeScalar(ctx context.Context, a any) (attr.Value, diag.Diagnostics) {
 }
 
 // decodeMapping decodes a mapping value into a Terraform attribute value.
-func decodeMapping(ctx context.Context, m map[string]any) (attr.Value, diag.Diagnostics) {
+func decodeMapping(ctx context.Context, m map[string]any, opts DecodeOptions) (attr.Value, diag.Diagnostics) {
 	l := len(m)
 	vm := make(map[string]attr.Value, l)
 	tm := make(map[string]attr.Type, l)
 
 	for k, v := range m {
-		vv, diags := decodeScalar(ctx, v)
+		vv, diags := decodeScalar(ctx, v, opts)
 		if diags.HasError() {
 			return nil, diags
 		}
@@ -72,17 +118,27 @@ func decodeMapping(ctx context.Context, m map[string]any) (attr.Value, diag.Diag
 		tm[k] = vv.Type(ctx)
 	}
 
+	if opts.CollectionMode == CollectionModeInfer {
+		if slices.ContainsFunc(slices.Collect(maps.Values(vm)), attr.Value.IsUnknown) {
+			return types.DynamicUnknown(), nil
+		}
+
+		if elemType, ok := commonType(slices.Collect(maps.Values(tm))); ok {
+			return types.MapValue(elemType, vm)
+		}
+	}
+
 	return types.ObjectValue(tm, vm)
 }
 
 // decodeSequence decodes a sequence value into a Terraform attribute value.
-func decodeSequence(ctx context.Context, s []any) (attr.Value, diag.Diagnostics) {
+func decodeSequence(ctx context.Context, s []any, opts DecodeOptions) (attr.Value, diag.Diagnostics) {
 	l := len(s)
 	vl := make([]attr.Value, l)
 	tl := make([]attr.Type, l)
 
 	for i, v := range s {
-		vv, err := decodeScalar(ctx, v)
+		vv, err := decodeScalar(ctx, v, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -90,5 +146,35 @@ func decodeSequence(ctx context.Context, s []any) (attr.Value, diag.Diagnostics)
 		tl[i] = vv.Type(ctx)
 	}
 
+	if opts.CollectionMode == CollectionModeInfer {
+		if slices.ContainsFunc(vl, attr.Value.IsUnknown) {
+			return types.DynamicUnknown(), nil
+		}
+
+		if elemType, ok := commonType(tl); ok {
+			return types.ListValue(elemType, vl)
+		}
+	}
+
 	return types.TupleValue(tl, vl)
 }
+
+// commonType returns the type shared by all of the element types; false is returned if there are no elements, the
+// types differ or the shared type is dynamic.
+func commonType(elemTypes []attr.Type) (attr.Type, bool) {
+	if len(elemTypes) == 0 {
+		return nil, false
+	}
+
+	for _, t := range elemTypes[1:] {
+		if !t.Equal(elemTypes[0]) {
+			return nil, false
+		}
+	}
+
+	if _, ok := elemTypes[0].(basetypes.DynamicType); ok {
+		return nil, false
+	}
+
+	return elemTypes[0], true
+}
@@ -1,6 +1,7 @@
 package tfdynamic
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"testing"
@@ -76,6 +77,30 @@ func TestDecode(t *testing.T) {
 			expected: types.DynamicValue(types.NumberValue(big.NewFloat(1.1))),
 			errMsg:   "",
 		},
+		{
+			testName: "int64_above_2_53",
+			obj:      int64(9007199254740993),
+			expected: types.DynamicValue(types.NumberValue(new(big.Float).SetInt64(9007199254740993))),
+			errMsg:   "",
+		},
+		{
+			testName: "json_number",
+			obj:      json.Number("9007199254740993"),
+			expected: types.DynamicValue(types.NumberValue(new(big.Float).SetInt64(9007199254740993))),
+			errMsg:   "",
+		},
+		{
+			testName: "json_number_invalid",
+			obj:      json.Number("abc"),
+			expected: types.Dynamic{},
+			errMsg:   "Invalid number.",
+		},
+		{
+			testName: "big_float",
+			obj:      big.NewFloat(1.5),
+			expected: types.DynamicValue(types.NumberValue(big.NewFloat(1.5))),
+			errMsg:   "",
+		},
 		{
 			testName: "bool",
 			obj:      true,
@@ -194,3 +219,111 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeWithOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	stringList, _ := types.ListValue(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")})
+	mixedTuple, _ := types.TupleValue([]attr.Type{types.StringType, types.BoolType}, []attr.Value{types.StringValue("a"), types.BoolValue(true)})
+	emptyTuple, _ := types.TupleValue([]attr.Type{}, []attr.Value{})
+	numberMap, _ := types.MapValue(types.NumberType, map[string]attr.Value{"a": types.NumberValue(big.NewFloat(1)), "b": types.NumberValue(big.NewFloat(2))})
+	mixedObject, _ := types.ObjectValue(map[string]attr.Type{"a": types.StringType, "b": types.NumberType}, map[string]attr.Value{"a": types.StringValue("x"), "b": types.NumberValue(big.NewFloat(1))})
+
+	itemType := types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "enabled": types.BoolType}}
+	item1, _ := types.ObjectValue(itemType.AttrTypes, map[string]attr.Value{"name": types.StringValue("a"), "enabled": types.BoolValue(true)})
+	item2, _ := types.ObjectValue(itemType.AttrTypes, map[string]attr.Value{"name": types.StringValue("b"), "enabled": types.BoolValue(false)})
+	itemMap, _ := types.MapValue(itemType, map[string]attr.Value{"a": item1, "b": item2})
+
+	stringTuple, _ := types.TupleValue([]attr.Type{types.StringType, types.StringType}, []attr.Value{types.StringValue("a"), types.StringValue("b")})
+	literalObject, _ := types.ObjectValue(map[string]attr.Type{"a": types.StringType}, map[string]attr.Value{"a": types.StringValue(UnknownStringLiteral)})
+	unknownObject, _ := types.ObjectValue(map[string]attr.Type{"a": types.DynamicType}, map[string]attr.Value{"a": types.DynamicUnknown()})
+
+	for _, d := range []struct {
+		testName string
+		obj      any
+		opts     DecodeOptions
+		expected types.Dynamic
+	}{
+		{
+			testName: "default_structural",
+			obj:      []any{"a", "b"},
+			opts:     DecodeOptions{},
+			expected: types.DynamicValue(stringTuple),
+		},
+		{
+			testName: "infer_list",
+			obj:      []any{"a", "b"},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(stringList),
+		},
+		{
+			testName: "infer_mixed_tuple",
+			obj:      []any{"a", true},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(mixedTuple),
+		},
+		{
+			testName: "infer_empty_tuple",
+			obj:      []any{},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(emptyTuple),
+		},
+		{
+			testName: "infer_map",
+			obj:      map[string]any{"a": json.Number("1"), "b": json.Number("2")},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(numberMap),
+		},
+		{
+			testName: "infer_mixed_object",
+			obj:      map[string]any{"a": "x", "b": json.Number("1")},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(mixedObject),
+		},
+		{
+			testName: "infer_nested_map",
+			obj:      map[string]any{"a": map[string]any{"name": "a", "enabled": true}, "b": map[string]any{"name": "b", "enabled": false}},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicValue(itemMap),
+		},
+		{
+			testName: "infer_with_unknown",
+			obj:      map[string]any{"items": []any{"a", UnknownStringLiteral}},
+			opts:     DecodeOptions{CollectionMode: CollectionModeInfer},
+			expected: types.DynamicUnknown(),
+		},
+		{
+			testName: "disable_unknown_string_literal",
+			obj:      map[string]any{"a": UnknownStringLiteral},
+			opts:     DecodeOptions{DisableUnknownStringLiteral: true},
+			expected: types.DynamicValue(literalObject),
+		},
+		{
+			testName: "unknown_marker",
+			obj:      map[string]any{"a": Unknown},
+			opts:     DecodeOptions{DisableUnknownStringLiteral: true},
+			expected: types.DynamicValue(unknownObject),
+		},
+		{
+			testName: "unknown_marker_root",
+			obj:      Unknown,
+			opts:     DecodeOptions{},
+			expected: types.DynamicUnknown(),
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			dyn, diags := DecodeWithOptions(ctx, d.obj, d.opts)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			if !dyn.Equal(d.expected) {
+				t.Errorf("expected %v, got %v", d.expected, dyn)
+			}
+		})
+	}
+}
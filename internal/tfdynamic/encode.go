@@ -2,7 +2,9 @@ package tfdynamic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -24,6 +26,17 @@ func EncodeDynamic(_ context.Context, d types.Dynamic) (any, error) {
 // encodeScalar encodes a scalar attribute value into an any value.
 func encodeScalar(v attr.Value) (any, error) {
 	switch val := v.(type) {
+	case types.Dynamic:
+		// Null elements decoded into a sequence, such as the placeholders left by splitting a list index, are dynamic.
+		if val.IsUnknown() {
+			return nil, fmt.Errorf("underlying value is unknown")
+		}
+
+		if val.IsNull() || val.IsUnderlyingValueNull() {
+			return nil, nil
+		}
+
+		return encodeScalar(val.UnderlyingValue())
 	case types.Bool:
 		return val.ValueBool(), nil
 	case types.String:
@@ -33,8 +46,7 @@ func encodeScalar(v attr.Value) (any, error) {
 	case types.Float64:
 		return val.ValueFloat64(), nil
 	case types.Number:
-		f, _ := val.ValueBigFloat().Float64()
-		return f, nil
+		return encodeNumber(val.ValueBigFloat()), nil
 	case types.List:
 		return encodeSequence(val.Elements())
 	case types.Set:
@@ -50,6 +62,19 @@ func encodeScalar(v attr.Value) (any, error) {
 	}
 }
 
+// encodeNumber encodes a number as an exact JSON number; integers are written without an exponent.
+func encodeNumber(f *big.Float) any {
+	if f == nil {
+		return nil
+	}
+
+	if f.IsInt() {
+		return json.Number(f.Text('f', 0))
+	}
+
+	return json.Number(f.Text('g', -1))
+}
+
 // encodeMapping encodes a map of attributes to a map of any.
 func encodeMapping(m map[string]attr.Value) (map[string]any, error) {
 	result := make(map[string]any, len(m))
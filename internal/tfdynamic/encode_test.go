@@ -1,6 +1,7 @@
 package tfdynamic
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
@@ -46,6 +47,21 @@ func TestEncodeDynamic(t *testing.T) {
 		},
 	)
 
+	tupleWithNull, _ := types.TupleValue(
+		[]attr.Type{types.StringType, types.DynamicType},
+		[]attr.Value{types.StringValue("a"), types.DynamicNull()},
+	)
+
+	tupleWithDynamic, _ := types.TupleValue(
+		[]attr.Type{types.DynamicType},
+		[]attr.Value{types.DynamicValue(types.StringValue("a"))},
+	)
+
+	tupleWithUnknown, _ := types.TupleValue(
+		[]attr.Type{types.DynamicType},
+		[]attr.Value{types.DynamicUnknown()},
+	)
+
 	for _, d := range []struct {
 		testName string
 		dyn      types.Dynamic
@@ -103,7 +119,19 @@ func TestEncodeDynamic(t *testing.T) {
 		{
 			testName: "number",
 			dyn:      types.DynamicValue(types.NumberValue(big.NewFloat(99.5))),
-			expected: float64(99.5),
+			expected: json.Number("99.5"),
+			errMsg:   "",
+		},
+		{
+			testName: "number_above_2_53",
+			dyn:      types.DynamicValue(types.NumberValue(new(big.Float).SetInt64(9007199254740993))),
+			expected: json.Number("9007199254740993"),
+			errMsg:   "",
+		},
+		{
+			testName: "number_null",
+			dyn:      types.DynamicValue(types.NumberNull()),
+			expected: nil,
 			errMsg:   "",
 		},
 		{
@@ -136,6 +164,24 @@ func TestEncodeDynamic(t *testing.T) {
 			expected: map[string]any{"k1": "v1"},
 			errMsg:   "",
 		},
+		{
+			testName: "tuple_with_null_dynamic",
+			dyn:      types.DynamicValue(tupleWithNull),
+			expected: []any{"a", nil},
+			errMsg:   "",
+		},
+		{
+			testName: "tuple_with_dynamic",
+			dyn:      types.DynamicValue(tupleWithDynamic),
+			expected: []any{"a"},
+			errMsg:   "",
+		},
+		{
+			testName: "tuple_with_unknown_dynamic",
+			dyn:      types.DynamicValue(tupleWithUnknown),
+			expected: []any(nil),
+			errMsg:   "underlying value is unknown",
+		},
 		{
 			testName: "nested_object_with_list",
 			dyn:      types.DynamicValue(nestedObject),
@@ -165,3 +211,54 @@ func TestEncodeDynamic(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeDynamic_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		number   json.Number
+	}{
+		{
+			testName: "max_safe_integer_plus_one",
+			number:   "9007199254740993",
+		},
+		{
+			testName: "snowflake",
+			number:   "1234567890123456789",
+		},
+		{
+			testName: "above_int64",
+			number:   "123456789012345678901234567890",
+		},
+		{
+			testName: "negative_above_2_53",
+			number:   "-9007199254740995",
+		},
+		{
+			testName: "decimal",
+			number:   "0.1",
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+
+			dyn, diags := Decode(ctx, map[string]any{"value": d.number})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags.Errors())
+			}
+
+			actual, err := EncodeDynamic(ctx, dyn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := map[string]any{"value": d.number}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package tfdynamic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// pathSegmentRegex matches a path segment made up of an optional object key followed by any list indexes.
+var pathSegmentRegex = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// pathIndexRegex matches a list index in a path segment.
+var pathIndexRegex = regexp.MustCompile(`\[(\d+)\]`)
+
+// pathStep is a single step in a path; if key is empty the step is a list index.
+type pathStep struct {
+	key   string
+	index int
+}
+
+// AttributePath parses a dot separated list of object keys with optional list indexes (e.g. endpoints[0].ip) into an
+// attribute path under the root.
+func AttributePath(root path.Path, p string) (path.Path, error) {
+	steps, err := parsePath(p)
+	if err != nil {
+		return path.Empty(), fmt.Errorf("invalid path %q: %w", p, err)
+	}
+
+	for _, step := range steps {
+		if len(step.key) > 0 {
+			root = root.AtName(step.key)
+			continue
+		}
+		root = root.AtListIndex(step.index)
+	}
+
+	return root, nil
+}
+
+// PathChanged returns true if the value at the path differs between the prior and planned values; a value which only
+// exists in one of them or isn't fully known in the planned value is treated as changed.
+func PathChanged(ctx context.Context, prior, planned types.Dynamic, p string) (bool, error) {
+	steps, err := parsePath(p)
+	if err != nil {
+		return false, fmt.Errorf("invalid path %q: %w", p, err)
+	}
+
+	priorValue, err := prior.ToTerraformValue(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	plannedValue, err := planned.ToTerraformValue(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	a, aok, err := walkTerraformValue(priorValue, steps)
+	if err != nil {
+		return false, err
+	}
+
+	b, bok, err := walkTerraformValue(plannedValue, steps)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case aok != bok:
+		return true, nil
+	case !aok:
+		return false, nil
+	case !b.IsFullyKnown():
+		return true, nil
+	default:
+		return !a.Equal(b), nil
+	}
+}
+
+// walkTerraformValue returns the value at the steps; false is returned if the value doesn't exist. An unknown value is
+// returned as is if the remaining steps can't be walked.
+func walkTerraformValue(v tftypes.Value, steps []pathStep) (tftypes.Value, bool, error) {
+	for _, step := range steps {
+		if !v.IsKnown() {
+			return v, true, nil
+		}
+
+		if v.IsNull() {
+			return tftypes.Value{}, false, nil
+		}
+
+		switch v.Type().(type) {
+		case tftypes.Object, tftypes.Map:
+			if len(step.key) == 0 {
+				return tftypes.Value{}, false, nil
+			}
+
+			m := map[string]tftypes.Value{}
+			if err := v.As(&m); err != nil {
+				return tftypes.Value{}, false, err
+			}
+
+			e, ok := m[step.key]
+			if !ok {
+				return tftypes.Value{}, false, nil
+			}
+			v = e
+		case tftypes.List, tftypes.Tuple:
+			if len(step.key) > 0 {
+				return tftypes.Value{}, false, nil
+			}
+
+			l := []tftypes.Value{}
+			if err := v.As(&l); err != nil {
+				return tftypes.Value{}, false, err
+			}
+
+			if step.index >= len(l) {
+				return tftypes.Value{}, false, nil
+			}
+			v = l[step.index]
+		default:
+			return tftypes.Value{}, false, nil
+		}
+	}
+
+	return v, true, nil
+}
+
+// parsePath parses a path into its steps.
+func parsePath(p string) ([]pathStep, error) {
+	var steps []pathStep
+	for i, segment := range strings.Split(p, ".") {
+		m := pathSegmentRegex.FindStringSubmatch(segment)
+		if m == nil {
+			return nil, fmt.Errorf("invalid segment %q", segment)
+		}
+
+		switch {
+		case len(m[1]) > 0:
+			steps = append(steps, pathStep{key: m[1]})
+		case i > 0 || len(m[2]) == 0:
+			return nil, errors.New("expected an object key")
+		}
+
+		for _, idx := range pathIndexRegex.FindAllStringSubmatch(m[2], -1) {
+			n, err := strconv.Atoi(idx[1])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{index: n})
+		}
+	}
+
+	return steps, nil
+}
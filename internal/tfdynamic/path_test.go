@@ -0,0 +1,167 @@
+package tfdynamic
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAttributePath(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		path     string
+		expected path.Path
+		errMsg   string
+	}{
+		{
+			testName: "key",
+			path:     "region",
+			expected: path.Root("inputs").AtName("region"),
+		},
+		{
+			testName: "nested_key",
+			path:     "disk.type",
+			expected: path.Root("inputs").AtName("disk").AtName("type"),
+		},
+		{
+			testName: "index",
+			path:     "disks[0].type",
+			expected: path.Root("inputs").AtName("disks").AtListIndex(0).AtName("type"),
+		},
+		{
+			testName: "invalid",
+			path:     "disks[x]",
+			expected: path.Empty(),
+			errMsg:   `invalid path "disks[x]": invalid segment "disks[x]"`,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := AttributePath(path.Root("inputs"), d.path)
+
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.errMsg {
+				t.Errorf("expected error %q, got %q", d.errMsg, errMsg)
+			}
+
+			if !p.Equal(d.expected) {
+				t.Errorf("expected %s, got %s", d.expected, p)
+			}
+		})
+	}
+}
+
+func TestPathChanged(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	object := func(region attr.Value, disks ...attr.Value) types.Dynamic {
+		diskTypes := make([]attr.Type, len(disks))
+		for i, d := range disks {
+			diskTypes[i] = d.Type(ctx)
+		}
+		disksValue, _ := types.TupleValue(diskTypes, disks)
+		v, _ := types.ObjectValue(
+			map[string]attr.Type{"region": region.Type(ctx), "disks": disksValue.Type(ctx)},
+			map[string]attr.Value{"region": region, "disks": disksValue},
+		)
+		return types.DynamicValue(v)
+	}
+	disk := func(diskType string) attr.Value {
+		v, _ := types.MapValue(types.StringType, map[string]attr.Value{"type": types.StringValue(diskType)})
+		return v
+	}
+
+	for _, d := range []struct {
+		testName string
+		prior    types.Dynamic
+		planned  types.Dynamic
+		path     string
+		expected bool
+		errMsg   string
+	}{
+		{
+			testName: "unchanged",
+			prior:    object(types.StringValue("a"), disk("ssd")),
+			planned:  object(types.StringValue("a"), disk("hdd")),
+			path:     "region",
+			expected: false,
+		},
+		{
+			testName: "changed",
+			prior:    object(types.StringValue("a")),
+			planned:  object(types.StringValue("b")),
+			path:     "region",
+			expected: true,
+		},
+		{
+			testName: "changed_nested",
+			prior:    object(types.StringValue("a"), disk("ssd")),
+			planned:  object(types.StringValue("a"), disk("hdd")),
+			path:     "disks[0].type",
+			expected: true,
+		},
+		{
+			testName: "added",
+			prior:    object(types.StringValue("a")),
+			planned:  object(types.StringValue("a"), disk("hdd")),
+			path:     "disks[0].type",
+			expected: true,
+		},
+		{
+			testName: "missing",
+			prior:    object(types.StringValue("a")),
+			planned:  object(types.StringValue("a")),
+			path:     "zone",
+			expected: false,
+		},
+		{
+			testName: "unknown",
+			prior:    object(types.StringValue("a")),
+			planned:  object(types.StringUnknown()),
+			path:     "region",
+			expected: true,
+		},
+		{
+			testName: "null_prior",
+			prior:    types.DynamicNull(),
+			planned:  object(types.StringValue("a")),
+			path:     "region",
+			expected: true,
+		},
+		{
+			testName: "invalid_path",
+			prior:    object(types.StringValue("a")),
+			planned:  object(types.StringValue("a")),
+			path:     "region[",
+			errMsg:   `invalid path "region[": invalid segment "region["`,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			changed, err := PathChanged(ctx, d.prior, d.planned, d.path)
+
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.errMsg {
+				t.Errorf("expected error %q, got %q", d.errMsg, errMsg)
+			}
+
+			if changed != d.expected {
+				t.Errorf("expected %t, got %t", d.expected, changed)
+			}
+		})
+	}
+}
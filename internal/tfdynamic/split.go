@@ -0,0 +1,126 @@
+package tfdynamic
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SplitPaths returns a copy of the object with the values at the paths removed, and an object containing only the
+// removed values; a path is a dot separated list of object keys with optional list indexes (e.g. users[0].password).
+// Removed list elements are replaced by nil in the remaining object and the other elements are nil in the split object
+// so the indexes are preserved. Paths which don't exist in the object are ignored; nil is returned for the split object
+// if no values were removed.
+func SplitPaths(obj any, paths []string) (any, any, error) {
+	var split any
+	for _, p := range paths {
+		steps, err := parsePath(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid path %q: %w", p, err)
+		}
+
+		obj, split, _ = splitPath(obj, split, steps)
+	}
+
+	return obj, split, nil
+}
+
+// splitPath moves the value at the steps from src to dst, cloning any objects and lists that are modified; false is
+// returned if the value doesn't exist in src.
+func splitPath(src, dst any, steps []pathStep) (any, any, bool) {
+	step := steps[0]
+	if len(step.key) > 0 {
+		m, ok := src.(map[string]any)
+		if !ok {
+			return src, dst, false
+		}
+
+		v, ok := m[step.key]
+		if !ok {
+			return src, dst, false
+		}
+
+		dm, _ := dst.(map[string]any)
+		dm = maps.Clone(dm)
+		if dm == nil {
+			dm = map[string]any{}
+		}
+
+		m = maps.Clone(m)
+		if len(steps) == 1 {
+			dm[step.key] = v
+			delete(m, step.key)
+			return m, dm, true
+		}
+
+		v, dv, ok := splitPath(v, dm[step.key], steps[1:])
+		if !ok {
+			return src, dst, false
+		}
+
+		m[step.key] = v
+		dm[step.key] = dv
+		return m, dm, true
+	}
+
+	s, ok := src.([]any)
+	if !ok || step.index >= len(s) {
+		return src, dst, false
+	}
+
+	ds, _ := dst.([]any)
+	ds = slices.Clone(ds)
+	if len(ds) < len(s) {
+		ds = append(ds, make([]any, len(s)-len(ds))...)
+	}
+
+	s = slices.Clone(s)
+	if len(steps) == 1 {
+		ds[step.index] = s[step.index]
+		s[step.index] = nil
+		return s, ds, true
+	}
+
+	v, dv, ok := splitPath(s[step.index], ds[step.index], steps[1:])
+	if !ok {
+		return src, dst, false
+	}
+
+	s[step.index] = v
+	ds[step.index] = dv
+	return s, ds, true
+}
+
+// MergePaths merges the split values back into the object; it reverses SplitPaths.
+func MergePaths(obj, split any) any {
+	if split == nil {
+		return obj
+	}
+
+	switch sv := split.(type) {
+	case map[string]any:
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return split
+		}
+
+		merged := maps.Clone(m)
+		for k, v := range sv {
+			merged[k] = MergePaths(merged[k], v)
+		}
+		return merged
+	case []any:
+		s, ok := obj.([]any)
+		if !ok || len(s) != len(sv) {
+			return split
+		}
+
+		merged := slices.Clone(s)
+		for i, v := range sv {
+			merged[i] = MergePaths(merged[i], v)
+		}
+		return merged
+	default:
+		return split
+	}
+}
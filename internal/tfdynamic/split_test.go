@@ -0,0 +1,178 @@
+package tfdynamic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName      string
+		obj           any
+		paths         []string
+		expected      any
+		expectedSplit any
+		errMsg        string
+	}{
+		{
+			testName: "no_paths",
+			obj:      map[string]any{"id": "a"},
+			paths:    nil,
+			expected: map[string]any{"id": "a"},
+		},
+		{
+			testName:      "key",
+			obj:           map[string]any{"id": "a", "password": "b"},
+			paths:         []string{"password"},
+			expected:      map[string]any{"id": "a"},
+			expectedSplit: map[string]any{"password": "b"},
+		},
+		{
+			testName:      "nested",
+			obj:           map[string]any{"auth": map[string]any{"user": "a", "password": "b"}},
+			paths:         []string{"auth.password"},
+			expected:      map[string]any{"auth": map[string]any{"user": "a"}},
+			expectedSplit: map[string]any{"auth": map[string]any{"password": "b"}},
+		},
+		{
+			testName:      "list_element",
+			obj:           map[string]any{"keys": []any{"a", "b", "c"}},
+			paths:         []string{"keys[1]"},
+			expected:      map[string]any{"keys": []any{"a", nil, "c"}},
+			expectedSplit: map[string]any{"keys": []any{nil, "b", nil}},
+		},
+		{
+			testName:      "list_elements",
+			obj:           map[string]any{"users": []any{map[string]any{"name": "a", "password": "b"}, map[string]any{"name": "c", "password": "d"}}},
+			paths:         []string{"users[0].password", "users[1].password"},
+			expected:      map[string]any{"users": []any{map[string]any{"name": "a"}, map[string]any{"name": "c"}}},
+			expectedSplit: map[string]any{"users": []any{map[string]any{"password": "b"}, map[string]any{"password": "d"}}},
+		},
+		{
+			testName:      "root_index",
+			obj:           []any{"a", "b"},
+			paths:         []string{"[0]"},
+			expected:      []any{nil, "b"},
+			expectedSplit: []any{"a", nil},
+		},
+		{
+			testName: "missing",
+			obj:      map[string]any{"auth": map[string]any{"user": "a"}, "keys": []any{"a"}},
+			paths:    []string{"id", "auth.password", "auth.user.name", "keys[1]", "keys.name"},
+			expected: map[string]any{"auth": map[string]any{"user": "a"}, "keys": []any{"a"}},
+		},
+		{
+			testName: "invalid_segment",
+			obj:      map[string]any{"id": "a"},
+			paths:    []string{"id[a]"},
+			errMsg:   `invalid path "id[a]": invalid segment "id[a]"`,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			obj, split, err := SplitPaths(d.obj, d.paths)
+
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.errMsg {
+				t.Errorf("expected error %q, got %q", d.errMsg, errMsg)
+			}
+
+			if len(d.errMsg) > 0 {
+				return
+			}
+
+			if !reflect.DeepEqual(obj, d.expected) {
+				t.Errorf("expected %#v, got %#v", d.expected, obj)
+			}
+
+			if !reflect.DeepEqual(split, d.expectedSplit) {
+				t.Errorf("expected split %#v, got %#v", d.expectedSplit, split)
+			}
+
+			if merged := MergePaths(obj, split); !reflect.DeepEqual(merged, d.obj) {
+				t.Errorf("expected merged %#v, got %#v", d.obj, merged)
+			}
+		})
+	}
+}
+
+func TestSplitPaths_DoesNotModifyInput(t *testing.T) {
+	t.Parallel()
+
+	obj := map[string]any{"users": []any{map[string]any{"name": "a", "password": "b"}}}
+	if _, _, err := SplitPaths(obj, []string{"users[0].password"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]any{"users": []any{map[string]any{"name": "a", "password": "b"}}}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+}
+
+func TestSplitPaths_DecodeEncode(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName      string
+		obj           any
+		paths         []string
+		expected      any
+		expectedSplit any
+	}{
+		{
+			testName:      "list_element",
+			obj:           map[string]any{"tokens": []any{"a", "b"}},
+			paths:         []string{"tokens[0]"},
+			expected:      map[string]any{"tokens": []any{nil, "b"}},
+			expectedSplit: map[string]any{"tokens": []any{"a", nil}},
+		},
+		{
+			testName:      "list_element_key",
+			obj:           map[string]any{"users": []any{map[string]any{"name": "a", "password": "b"}, map[string]any{"name": "c"}}},
+			paths:         []string{"users[0].password"},
+			expected:      map[string]any{"users": []any{map[string]any{"name": "a"}, map[string]any{"name": "c"}}},
+			expectedSplit: map[string]any{"users": []any{map[string]any{"password": "b"}, nil}},
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+
+			obj, split, err := SplitPaths(d.obj, d.paths)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, v := range []struct {
+				name     string
+				value    any
+				expected any
+			}{
+				{name: "object", value: obj, expected: d.expected},
+				{name: "split", value: split, expected: d.expectedSplit},
+			} {
+				dyn, diags := Decode(ctx, v.value)
+				if diags.HasError() {
+					t.Fatalf("unexpected %s decode error: %v", v.name, diags.Errors())
+				}
+
+				actual, err := EncodeDynamic(ctx, dyn)
+				if err != nil {
+					t.Fatalf("unexpected %s encode error: %v", v.name, err)
+				}
+
+				if !reflect.DeepEqual(actual, v.expected) {
+					t.Errorf("expected %s %#v, got %#v", v.name, v.expected, actual)
+				}
+			}
+		})
+	}
+}
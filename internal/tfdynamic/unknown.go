@@ -0,0 +1,77 @@
+package tfdynamic
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// unknownValue is the type of the Unknown marker value.
+type unknownValue struct{}
+
+// Unknown is a marker value which is decoded as an unknown value of any type.
+var Unknown any = unknownValue{}
+
+// SetUnknownPaths returns a copy of the object with the values at the paths set to Unknown; a path is a dot separated
+// list of object keys with optional list indexes (e.g. endpoints[0].ip). Missing object keys are created.
+func SetUnknownPaths(obj any, paths []string) (any, error) {
+	for _, p := range paths {
+		steps, err := parsePath(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", p, err)
+		}
+
+		obj, err = setUnknown(obj, steps)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", p, err)
+		}
+	}
+
+	return obj, nil
+}
+
+// setUnknown returns a copy of the object with the value at the steps set to Unknown.
+func setUnknown(obj any, steps []pathStep) (any, error) {
+	if len(steps) == 0 || obj == Unknown {
+		return Unknown, nil
+	}
+
+	step := steps[0]
+	if len(step.key) > 0 {
+		if obj == nil {
+			obj = map[string]any{}
+		}
+
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object for key %q", step.key)
+		}
+
+		v, err := setUnknown(m[step.key], steps[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		m = maps.Clone(m)
+		m[step.key] = v
+		return m, nil
+	}
+
+	s, ok := obj.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array for index %d", step.index)
+	}
+
+	if step.index >= len(s) {
+		return nil, fmt.Errorf("index %d out of range", step.index)
+	}
+
+	v, err := setUnknown(s[step.index], steps[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	s = slices.Clone(s)
+	s[step.index] = v
+	return s, nil
+}
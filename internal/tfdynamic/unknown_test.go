@@ -0,0 +1,130 @@
+package tfdynamic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetUnknownPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []struct {
+		testName string
+		obj      any
+		paths    []string
+		expected any
+		errMsg   string
+	}{
+		{
+			testName: "no_paths",
+			obj:      map[string]any{"id": "a"},
+			paths:    nil,
+			expected: map[string]any{"id": "a"},
+		},
+		{
+			testName: "key",
+			obj:      map[string]any{"id": "a", "name": "b"},
+			paths:    []string{"id"},
+			expected: map[string]any{"id": Unknown, "name": "b"},
+		},
+		{
+			testName: "missing_key",
+			obj:      map[string]any{"name": "b"},
+			paths:    []string{"id"},
+			expected: map[string]any{"id": Unknown, "name": "b"},
+		},
+		{
+			testName: "nested",
+			obj:      map[string]any{"endpoints": []any{map[string]any{"ip": "", "port": "80"}}},
+			paths:    []string{"endpoints[0].ip"},
+			expected: map[string]any{"endpoints": []any{map[string]any{"ip": Unknown, "port": "80"}}},
+		},
+		{
+			testName: "whole_list",
+			obj:      map[string]any{"endpoints": []any{"a"}, "tags": map[string]any{"env": "dev"}},
+			paths:    []string{"endpoints", "tags"},
+			expected: map[string]any{"endpoints": Unknown, "tags": Unknown},
+		},
+		{
+			testName: "nested_index",
+			obj:      map[string]any{"matrix": []any{[]any{"a", "b"}}},
+			paths:    []string{"matrix[0][1]"},
+			expected: map[string]any{"matrix": []any{[]any{"a", Unknown}}},
+		},
+		{
+			testName: "root_index",
+			obj:      []any{"a", "b"},
+			paths:    []string{"[1]"},
+			expected: []any{"a", Unknown},
+		},
+		{
+			testName: "inside_unknown",
+			obj:      map[string]any{"endpoints": []any{map[string]any{"ip": ""}}},
+			paths:    []string{"endpoints", "endpoints[0].ip"},
+			expected: map[string]any{"endpoints": Unknown},
+		},
+		{
+			testName: "index_out_of_range",
+			obj:      map[string]any{"endpoints": []any{}},
+			paths:    []string{"endpoints[0]"},
+			errMsg:   `invalid path "endpoints[0]": index 0 out of range`,
+		},
+		{
+			testName: "not_an_object",
+			obj:      map[string]any{"id": "a"},
+			paths:    []string{"id.value"},
+			errMsg:   `invalid path "id.value": expected an object for key "value"`,
+		},
+		{
+			testName: "not_an_array",
+			obj:      map[string]any{"id": "a"},
+			paths:    []string{"id[0]"},
+			errMsg:   `invalid path "id[0]": expected an array for index 0`,
+		},
+		{
+			testName: "empty_segment",
+			obj:      map[string]any{"id": "a"},
+			paths:    []string{"id..value"},
+			errMsg:   `invalid path "id..value": expected an object key`,
+		},
+		{
+			testName: "invalid_segment",
+			obj:      map[string]any{"id": "a"},
+			paths:    []string{"id[a]"},
+			errMsg:   `invalid path "id[a]": invalid segment "id[a]"`,
+		},
+	} {
+		t.Run(d.testName, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := SetUnknownPaths(d.obj, d.paths)
+
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != d.errMsg {
+				t.Errorf("expected error %q, got %q", d.errMsg, errMsg)
+			}
+
+			if !reflect.DeepEqual(obj, d.expected) {
+				t.Errorf("expected %#v, got %#v", d.expected, obj)
+			}
+		})
+	}
+}
+
+func TestSetUnknownPaths_DoesNotModifyInput(t *testing.T) {
+	t.Parallel()
+
+	obj := map[string]any{"endpoints": []any{map[string]any{"ip": "a"}}}
+
+	if _, err := SetUnknownPaths(obj, []string{"endpoints[0].ip"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]any{"endpoints": []any{map[string]any{"ip": "a"}}}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+}